@@ -0,0 +1,7 @@
+-- ID unik dari bank (misal FITID di file OFX), agar impor ulang tidak membuat duplikat
+ALTER TABLE transactions ADD COLUMN external_id VARCHAR(255) DEFAULT NULL;
+
+-- Satu external_id hanya boleh muncul sekali per akun
+CREATE UNIQUE INDEX idx_transactions_account_external_id
+    ON transactions(account_id, external_id)
+    WHERE external_id IS NOT NULL;
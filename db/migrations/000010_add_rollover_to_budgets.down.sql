@@ -0,0 +1,3 @@
+ALTER TABLE budgets DROP CONSTRAINT IF EXISTS budgets_rollover_check;
+ALTER TABLE budgets DROP COLUMN IF EXISTS rollover_cap;
+ALTER TABLE budgets DROP COLUMN IF EXISTS rollover;
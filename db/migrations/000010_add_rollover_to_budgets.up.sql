@@ -0,0 +1,9 @@
+-- Aturan sisa budget ke bulan berikutnya:
+-- 'none', 'surplus' (sisa positif dibawa), 'surplus_and_deficit' (sisa & kekurangan dibawa),
+-- 'capped' (sisa positif dibawa maksimal rollover_cap)
+ALTER TABLE budgets
+    ADD COLUMN rollover VARCHAR(30) NOT NULL DEFAULT 'none',
+    ADD COLUMN rollover_cap BIGINT DEFAULT NULL;
+
+ALTER TABLE budgets ADD CONSTRAINT budgets_rollover_check
+    CHECK (rollover IN ('none', 'surplus', 'surplus_and_deficit', 'capped'));
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS budget_template_items;
+DROP TABLE IF EXISTS budget_templates;
@@ -0,0 +1,16 @@
+CREATE TABLE budget_templates (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE, -- misal 'Bulan Normal', 'Bulan Ramadan'
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE budget_template_items (
+    id SERIAL PRIMARY KEY,
+    template_id INT NOT NULL REFERENCES budget_templates(id) ON DELETE CASCADE,
+    category_name VARCHAR(50) NOT NULL,
+    amount BIGINT NOT NULL, -- dalam 'sen'
+    rollover VARCHAR(30) NOT NULL DEFAULT 'none',
+    rollover_cap BIGINT DEFAULT NULL,
+
+    UNIQUE(template_id, category_name)
+);
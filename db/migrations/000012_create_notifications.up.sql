@@ -0,0 +1,25 @@
+-- Setiap ambang budget (80%, 100%) hanya dicatat sekali per kategori per bulan
+CREATE TABLE budget_alerts (
+    id SERIAL PRIMARY KEY,
+    category_name VARCHAR(50) NOT NULL,
+    month INT NOT NULL,
+    year INT NOT NULL,
+    threshold INT NOT NULL, -- dalam persen
+    spent BIGINT NOT NULL, -- dalam 'sen', saat ambang terlewati
+    budget_limit BIGINT NOT NULL, -- dalam 'sen', termasuk rollover
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE(category_name, month, year, threshold)
+);
+
+CREATE TABLE notifications (
+    id SERIAL PRIMARY KEY,
+    type VARCHAR(30) NOT NULL, -- misal 'budget_alert'
+    title VARCHAR(200) NOT NULL,
+    message TEXT NOT NULL,
+    data JSONB,
+    read_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_unread ON notifications (created_at DESC) WHERE read_at IS NULL;
@@ -0,0 +1,16 @@
+ALTER TABLE budget_alerts DROP CONSTRAINT IF EXISTS budget_alerts_period_key;
+DELETE FROM budget_alerts a USING budget_alerts b
+    WHERE a.id > b.id AND a.category_name = b.category_name
+        AND a.month = b.month AND a.year = b.year AND a.threshold = b.threshold;
+ALTER TABLE budget_alerts ADD CONSTRAINT budget_alerts_category_name_month_year_threshold_key
+    UNIQUE (category_name, month, year, threshold);
+ALTER TABLE budget_alerts DROP COLUMN IF EXISTS period_start;
+ALTER TABLE budget_alerts DROP COLUMN IF EXISTS period_type;
+
+ALTER TABLE budget_template_items DROP COLUMN IF EXISTS period_start_day;
+ALTER TABLE budget_template_items DROP COLUMN IF EXISTS period_type;
+
+ALTER TABLE budgets DROP CONSTRAINT IF EXISTS budgets_period_rollover_check;
+ALTER TABLE budgets DROP CONSTRAINT IF EXISTS budgets_period_check;
+ALTER TABLE budgets DROP COLUMN IF EXISTS period_start_day;
+ALTER TABLE budgets DROP COLUMN IF EXISTS period_type;
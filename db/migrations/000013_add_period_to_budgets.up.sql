@@ -0,0 +1,35 @@
+-- Jenis periode budget. Baris budget tetap dicatat per (bulan, tahun) awal periodenya:
+-- 'monthly' = bulan kalender,
+-- 'custom'  = mulai tanggal period_start_day (misal tanggal gajian) sampai sehari sebelum
+--             tanggal yang sama di bulan berikutnya,
+-- 'yearly'  = 12 bulan mulai tanggal 1 bulan tersebut,
+-- 'weekly'  = amount per minggu, berlaku untuk setiap minggu di bulan tersebut;
+--             minggu dimulai hari period_start_day (1 = Senin ... 7 = Minggu)
+ALTER TABLE budgets
+    ADD COLUMN period_type VARCHAR(20) NOT NULL DEFAULT 'monthly',
+    ADD COLUMN period_start_day INT DEFAULT NULL;
+
+ALTER TABLE budgets ADD CONSTRAINT budgets_period_check CHECK (
+    (period_type IN ('monthly', 'yearly') AND period_start_day IS NULL)
+    OR (period_type = 'custom' AND period_start_day BETWEEN 1 AND 31)
+    OR (period_type = 'weekly' AND period_start_day BETWEEN 1 AND 7)
+);
+
+-- Rollover berantai dari bulan ke bulan, jadi hanya untuk periode bulanan
+ALTER TABLE budgets ADD CONSTRAINT budgets_period_rollover_check
+    CHECK (rollover = 'none' OR period_type IN ('monthly', 'custom'));
+
+ALTER TABLE budget_template_items
+    ADD COLUMN period_type VARCHAR(20) NOT NULL DEFAULT 'monthly',
+    ADD COLUMN period_start_day INT DEFAULT NULL;
+
+-- Ambang budget dicatat sekali per periode (misal per minggu untuk budget mingguan)
+ALTER TABLE budget_alerts
+    ADD COLUMN period_type VARCHAR(20) NOT NULL DEFAULT 'monthly',
+    ADD COLUMN period_start DATE;
+UPDATE budget_alerts SET period_start = make_date(year, month, 1);
+ALTER TABLE budget_alerts ALTER COLUMN period_start SET NOT NULL;
+
+ALTER TABLE budget_alerts DROP CONSTRAINT IF EXISTS budget_alerts_category_name_month_year_threshold_key;
+ALTER TABLE budget_alerts ADD CONSTRAINT budget_alerts_period_key
+    UNIQUE (category_name, period_type, period_start, threshold);
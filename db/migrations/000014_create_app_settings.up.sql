@@ -0,0 +1,12 @@
+-- Pengaturan yang berlaku untuk seluruh aplikasi. Budget dan transaksi dipakai bersama oleh
+-- semua pengguna, jadi bulan keuangan tidak bisa berbeda per pengguna tanpa membuat angka
+-- progres & rollover berbeda untuk data yang sama. Tabel ini selalu berisi tepat satu baris.
+CREATE TABLE app_settings (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    -- Tanggal awal bulan keuangan, misal 25 untuk yang gajian tanggal 25.
+    -- Tanggal yang tidak ada di sebuah bulan (29-31) dimundurkan ke hari terakhir bulan itu.
+    month_start_day INT NOT NULL DEFAULT 1 CHECK (month_start_day BETWEEN 1 AND 31),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO app_settings DEFAULT VALUES;
@@ -0,0 +1,2 @@
+-- Zona waktu IANA untuk batas hari, parsing tanggal, laporan dan jadwal berulang
+ALTER TABLE app_settings ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta';
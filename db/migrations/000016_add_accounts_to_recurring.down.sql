@@ -0,0 +1,4 @@
+ALTER TABLE recurring_transactions DROP CONSTRAINT IF EXISTS recurring_transactions_destination_check;
+ALTER TABLE recurring_transactions
+    DROP COLUMN IF EXISTS destination_account_id,
+    DROP COLUMN IF EXISTS account_id;
@@ -0,0 +1,16 @@
+-- Jadwal berulang sekarang punya akun sumber, dan akun tujuan untuk transfer berulang (misal tabungan bulanan)
+ALTER TABLE recurring_transactions
+    ADD COLUMN account_id INT REFERENCES accounts(id),
+    ADD COLUMN destination_account_id INT REFERENCES accounts(id) DEFAULT NULL;
+
+-- Jadwal lama dihubungkan ke akun pertama (sama seperti transaksi lama di migrasi 000007)
+UPDATE recurring_transactions SET account_id = (SELECT MIN(id) FROM accounts);
+
+ALTER TABLE recurring_transactions ALTER COLUMN account_id SET NOT NULL;
+
+-- Hanya transfer yang punya akun tujuan, dan tujuannya harus akun lain
+ALTER TABLE recurring_transactions
+    ADD CONSTRAINT recurring_transactions_destination_check CHECK (
+        (type = 'transfer' AND destination_account_id IS NOT NULL AND destination_account_id <> account_id)
+        OR (type <> 'transfer' AND destination_account_id IS NULL)
+    );
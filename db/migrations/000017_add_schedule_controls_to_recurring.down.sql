@@ -0,0 +1,5 @@
+ALTER TABLE recurring_transactions
+    DROP COLUMN IF EXISTS paused_at,
+    DROP COLUMN IF EXISTS occurrences,
+    DROP COLUMN IF EXISTS max_occurrences,
+    DROP COLUMN IF EXISTS end_date;
@@ -0,0 +1,8 @@
+-- Kontrol jadwal berulang: jeda, tanggal berakhir dan batas jumlah kejadian
+ALTER TABLE recurring_transactions
+    ADD COLUMN end_date DATE DEFAULT NULL,
+    ADD COLUMN max_occurrences INT DEFAULT NULL
+        CONSTRAINT recurring_transactions_max_occurrences_check CHECK (max_occurrences > 0),
+    -- Jumlah transaksi yang sudah diposting worker (yang di-skip tidak dihitung)
+    ADD COLUMN occurrences INT NOT NULL DEFAULT 0,
+    ADD COLUMN paused_at TIMESTAMPTZ DEFAULT NULL;
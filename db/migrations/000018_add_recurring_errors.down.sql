@@ -0,0 +1,4 @@
+ALTER TABLE recurring_transactions DROP CONSTRAINT IF EXISTS recurring_transactions_frequency_check;
+ALTER TABLE recurring_transactions
+    DROP COLUMN IF EXISTS last_error_at,
+    DROP COLUMN IF EXISTS last_error;
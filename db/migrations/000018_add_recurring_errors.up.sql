@@ -0,0 +1,10 @@
+-- Error terakhir saat worker memproses jadwal ini (dikosongkan lagi setelah berhasil)
+ALTER TABLE recurring_transactions
+    ADD COLUMN last_error TEXT DEFAULT NULL,
+    ADD COLUMN last_error_at TIMESTAMPTZ DEFAULT NULL;
+
+-- Frequency & interval divalidasi saat membuat jadwal. NOT VALID: baris lama tidak dicek,
+-- jadwal lama yang rusak akan dicatat sebagai error oleh worker.
+ALTER TABLE recurring_transactions
+    ADD CONSTRAINT recurring_transactions_frequency_check
+        CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly') AND "interval" >= 1) NOT VALID;
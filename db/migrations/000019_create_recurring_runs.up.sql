@@ -0,0 +1,15 @@
+-- Log setiap kali worker transaksi berulang berjalan (cron atau manual)
+CREATE TABLE recurring_runs (
+    id SERIAL PRIMARY KEY,
+    trigger VARCHAR(10) NOT NULL,                  -- 'cron' atau 'manual'
+    status VARCHAR(10) NOT NULL DEFAULT 'running', -- 'running', 'completed' atau 'failed'
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ DEFAULT NULL,
+    schedules INT NOT NULL DEFAULT 0,
+    posted INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    errors JSONB NOT NULL DEFAULT '[]',            -- Error per jadwal
+    error TEXT DEFAULT NULL                        -- Error yang menghentikan seluruh run
+);
+
+CREATE INDEX idx_recurring_runs_started_at ON recurring_runs(started_at DESC);
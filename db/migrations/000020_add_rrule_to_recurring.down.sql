@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS holidays;
+ALTER TABLE recurring_transactions
+    DROP COLUMN IF EXISTS business_day_shift,
+    DROP COLUMN IF EXISTS rrule;
@@ -0,0 +1,16 @@
+-- Aturan pengulangan RFC 5545 (misal 'FREQ=MONTHLY;BYDAY=FR;BYSETPOS=2'). Jika diisi,
+-- frequency & "interval" disalin dari FREQ & INTERVAL aturan ini.
+-- COUNT dan UNTIL disimpan sebagai max_occurrences dan end_date.
+ALTER TABLE recurring_transactions
+    ADD COLUMN rrule TEXT DEFAULT NULL,
+    -- Geser kejadian yang jatuh di akhir pekan/hari libur ke hari kerja sebelum/sesudahnya
+    ADD COLUMN business_day_shift VARCHAR(10) NOT NULL DEFAULT 'none'
+        CONSTRAINT recurring_transactions_business_day_shift_check
+        CHECK (business_day_shift IN ('none', 'previous', 'next'));
+
+-- Hari libur (selain Sabtu & Minggu) untuk pergeseran hari kerja
+CREATE TABLE holidays (
+    date DATE PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- Token rahasia untuk URL feed iCalendar (/api/calendar/{token}.ics). NULL = feed tidak aktif.
+ALTER TABLE users ADD COLUMN calendar_token VARCHAR(64) DEFAULT NULL;
+CREATE UNIQUE INDEX idx_users_calendar_token ON users(calendar_token);
@@ -0,0 +1,3 @@
+-- Hanya admin yang boleh memakai endpoint /api/admin. Pengguna pertama menjadi admin.
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE users SET is_admin = TRUE WHERE id = (SELECT MIN(id) FROM users);
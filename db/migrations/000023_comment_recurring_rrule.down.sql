@@ -0,0 +1 @@
+COMMENT ON COLUMN recurring_transactions.rrule IS NULL;
@@ -0,0 +1,5 @@
+-- Komentar di 000020 sudah tidak berlaku: COUNT dan UNTIL tetap disimpan di dalam rrule
+-- (tidak dipindah ke max_occurrences/end_date) dan berlaku untuk kejadian nominal,
+-- sebelum digeser ke hari kerja.
+COMMENT ON COLUMN recurring_transactions.rrule IS
+    'Aturan pengulangan RFC 5545. COUNT dan UNTIL tetap di dalam aturan ini (dihitung dari start_date) dan berlaku sebelum pergeseran business_day_shift; frequency dan interval disalin dari FREQ dan INTERVAL.';
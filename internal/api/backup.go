@@ -0,0 +1,782 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxBackupSize adalah ukuran maksimum arsip yang bisa di-restore
+const maxBackupSize = 100 << 20
+
+// recurringFrequencies adalah nilai frequency yang dikenal worker recurring
+var recurringFrequencies = map[string]bool{"daily": true, "weekly": true, "monthly": true, "yearly": true}
+
+// CreateBackup membaca seluruh data di dalam satu snapshot (REPEATABLE READ) agar saldo
+// akun dan transaksi di arsip selalu konsisten satu sama lain.
+func (s *Store) CreateBackup(ctx context.Context) (*models.Backup, error) {
+	tx, err := s.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	backup := &models.Backup{
+		Format:       models.BackupFormat,
+		Version:      models.BackupVersion,
+		CreatedAt:    time.Now().UTC(),
+		Accounts:     make([]models.Account, 0),
+		Categories:   make([]models.Category, 0),
+		Transactions: make([]models.Transaction, 0),
+		Budgets:      make([]models.Budget, 0),
+		Recurring:    make([]models.RecurringTransaction, 0),
+
+		BudgetTemplates: make([]models.BudgetTemplate, 0),
+		Holidays:        make([]models.Holiday, 0),
+	}
+
+	// 1. Akun
+	rows, err := tx.Query(ctx, `SELECT id, name, COALESCE(type, ''), current_balance, created_at FROM accounts ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var acc models.Account
+		if err := rows.Scan(&acc.ID, &acc.Name, &acc.Type, &acc.CurrentBalance, &acc.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		backup.Accounts = append(backup.Accounts, acc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 2. Kategori
+	rows, err = tx.Query(ctx, `SELECT id, name, created_at FROM categories ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var cat models.Category
+		if err := rows.Scan(&cat.ID, &cat.Name, &cat.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		backup.Categories = append(backup.Categories, cat)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 3. Transaksi
+	rows, err = tx.Query(ctx, `
+		SELECT id, amount, type, category, COALESCE(description, ''), date, created_at,
+		       account_id, destination_account_id, external_id
+		FROM transactions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var t models.Transaction
+		if err := rows.Scan(&t.ID, &t.Amount, &t.Type, &t.Category, &t.Description, &t.Date, &t.CreatedAt,
+			&t.AccountID, &t.DestinationAccountID, &t.ExternalID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		backup.Transactions = append(backup.Transactions, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 4. Budget
+	rows, err = tx.Query(ctx, `SELECT `+budgetColumns+` FROM budgets ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var b models.Budget
+		if err := rows.Scan(&b.ID, &b.CategoryName, &b.Amount, &b.Month, &b.Year, &b.Rollover, &b.RolloverCap,
+			&b.PeriodType, &b.PeriodStartDay, &b.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		backup.Budgets = append(backup.Budgets, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 5. Transaksi berulang
+	rows, err = tx.Query(ctx, `
+		SELECT id, amount, type, category, COALESCE(description, ''), account_id, destination_account_id,
+		       frequency, "interval", COALESCE(rrule, ''), business_day_shift, start_date, next_due_date, end_date, max_occurrences,
+		       occurrences, paused_at, created_at
+		FROM recurring_transactions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var rt models.RecurringTransaction
+		if err := rows.Scan(&rt.ID, &rt.Amount, &rt.Type, &rt.Category, &rt.Description, &rt.AccountID,
+			&rt.DestinationAccountID, &rt.Frequency, &rt.Interval, &rt.RRule, &rt.BusinessDayShift, &rt.StartDate, &rt.NextDueDate, &rt.EndDate,
+			&rt.MaxOccurrences, &rt.Occurrences, &rt.PausedAt, &rt.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		setRecurringStatus(&rt)
+		backup.Recurring = append(backup.Recurring, rt)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 6. Template budget beserta isinya
+	rows, err = tx.Query(ctx, `SELECT id, name, created_at FROM budget_templates ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var t models.BudgetTemplate
+		if err := rows.Scan(&t.ID, &t.Name, &t.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		backup.BudgetTemplates = append(backup.BudgetTemplates, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := loadTemplateItems(ctx, tx, backup.BudgetTemplates); err != nil {
+		return nil, err
+	}
+
+	// 7. Hari libur
+	rows, err = tx.Query(ctx, `SELECT to_char(date, 'YYYY-MM-DD'), name, created_at FROM holidays ORDER BY date`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var h models.Holiday
+		if err := rows.Scan(&h.Date, &h.Name, &h.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		backup.Holidays = append(backup.Holidays, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 8. Pengaturan aplikasi
+	var settings models.AppSettings
+	if err := tx.QueryRow(ctx, `SELECT month_start_day, timezone FROM app_settings`).
+		Scan(&settings.MonthStartDay, &settings.Timezone); err != nil {
+		return nil, err
+	}
+	backup.Settings = &settings
+
+	return backup, tx.Commit(ctx)
+}
+
+// BackupValidationError dikembalikan jika isi arsip tidak valid.
+// Tidak ada data yang diubah jika error ini muncul.
+type BackupValidationError struct {
+	Problems []string
+}
+
+func (e *BackupValidationError) Error() string {
+	return fmt.Sprintf("backup archive is invalid (%d problems)", len(e.Problems))
+}
+
+// validateBackup memeriksa versi arsip dan relasi antar data sebelum restore dimulai
+func validateBackup(b *models.Backup) error {
+	if b.Format != models.BackupFormat {
+		return &BackupValidationError{Problems: []string{fmt.Sprintf("unknown archive format %q", b.Format)}}
+	}
+	if b.Version < 1 || b.Version > models.BackupVersion {
+		return &BackupValidationError{Problems: []string{
+			fmt.Sprintf("unsupported archive version %d, this server supports up to version %d", b.Version, models.BackupVersion),
+		}}
+	}
+
+	var problems []string
+	accountIDs := make(map[int64]bool)
+	accountNames := make(map[string]bool)
+	for i, acc := range b.Accounts {
+		name := strings.ToLower(strings.TrimSpace(acc.Name))
+		switch {
+		case name == "":
+			problems = append(problems, fmt.Sprintf("accounts[%d]: name is required", i))
+		case accountIDs[acc.ID]:
+			problems = append(problems, fmt.Sprintf("accounts[%d]: duplicate id %d", i, acc.ID))
+		case accountNames[name]:
+			problems = append(problems, fmt.Sprintf("accounts[%d]: duplicate name %q", i, acc.Name))
+		}
+		accountIDs[acc.ID] = true
+		accountNames[name] = true
+	}
+
+	for i, cat := range b.Categories {
+		if strings.TrimSpace(cat.Name) == "" || len(cat.Name) > 50 {
+			problems = append(problems, fmt.Sprintf("categories[%d]: name must be 1-50 characters", i))
+		}
+	}
+
+	externalIDs := make(map[string]bool)
+	for i := range b.Transactions {
+		t := &b.Transactions[i]
+		if err := validateTransactionRow(t); err != nil {
+			problems = append(problems, fmt.Sprintf("transactions[%d]: %v", i, err))
+			continue
+		}
+		if t.ExternalID != nil {
+			key := fmt.Sprintf("%d|%s", t.AccountID, *t.ExternalID)
+			if externalIDs[key] {
+				problems = append(problems, fmt.Sprintf("transactions[%d]: duplicate external_id %q", i, *t.ExternalID))
+			}
+			externalIDs[key] = true
+		}
+		if !accountIDs[t.AccountID] {
+			problems = append(problems, fmt.Sprintf("transactions[%d]: account %d is not in the archive", i, t.AccountID))
+		} else if t.DestinationAccountID != nil && !accountIDs[*t.DestinationAccountID] {
+			problems = append(problems, fmt.Sprintf("transactions[%d]: destination account %d is not in the archive", i, *t.DestinationAccountID))
+		}
+	}
+
+	for i := range b.Budgets {
+		bud := &b.Budgets[i]
+		if bud.CategoryName == "" || bud.Month < 1 || bud.Month > 12 || bud.Year < 1 || bud.Amount < 0 {
+			problems = append(problems, fmt.Sprintf("budgets[%d]: invalid category, month, year or amount", i))
+		} else if err := validRollover(bud); err != nil {
+			problems = append(problems, fmt.Sprintf("budgets[%d]: %v", i, err))
+		} else if err := validPeriod(bud); err != nil {
+			problems = append(problems, fmt.Sprintf("budgets[%d]: %v", i, err))
+		}
+	}
+
+	for i := range b.Recurring {
+		rt := &b.Recurring[i]
+		// Arsip lama belum menyimpan akun jadwal berulang; pakai akun pertama di arsip
+		if rt.AccountID == 0 && len(b.Accounts) > 0 {
+			rt.AccountID = b.Accounts[0].ID
+		}
+		if err := validateRecurring(rt); err != nil {
+			problems = append(problems, fmt.Sprintf("recurring[%d]: %v", i, err))
+		} else if !accountIDs[rt.AccountID] {
+			problems = append(problems, fmt.Sprintf("recurring[%d]: account %d is not in the archive", i, rt.AccountID))
+		} else if rt.DestinationAccountID != nil && !accountIDs[*rt.DestinationAccountID] {
+			problems = append(problems, fmt.Sprintf("recurring[%d]: destination account %d is not in the archive", i, *rt.DestinationAccountID))
+		}
+	}
+
+	templateNames := make(map[string]bool)
+	for i := range b.BudgetTemplates {
+		t := &b.BudgetTemplates[i]
+		t.Name = strings.TrimSpace(t.Name)
+		switch {
+		case t.Name == "" || len(t.Name) > 100:
+			problems = append(problems, fmt.Sprintf("budget_templates[%d]: name must be 1-100 characters", i))
+		case templateNames[t.Name]:
+			problems = append(problems, fmt.Sprintf("budget_templates[%d]: duplicate name %q", i, t.Name))
+		default:
+			if err := validateBudgetItems(t.Items); err != nil {
+				problems = append(problems, fmt.Sprintf("budget_templates[%d]: %v", i, err))
+			}
+		}
+		templateNames[t.Name] = true
+	}
+
+	holidayDates := make(map[string]bool)
+	for i := range b.Holidays {
+		h := &b.Holidays[i]
+		h.Name = strings.TrimSpace(h.Name)
+		if _, err := time.Parse("2006-01-02", h.Date); err != nil {
+			problems = append(problems, fmt.Sprintf("holidays[%d]: date must be YYYY-MM-DD", i))
+		} else if h.Name == "" || len(h.Name) > 100 {
+			problems = append(problems, fmt.Sprintf("holidays[%d]: name must be 1-100 characters", i))
+		} else if holidayDates[h.Date] {
+			problems = append(problems, fmt.Sprintf("holidays[%d]: duplicate date %s", i, h.Date))
+		}
+		holidayDates[h.Date] = true
+	}
+
+	if b.Settings != nil {
+		if b.Settings.MonthStartDay < 1 || b.Settings.MonthStartDay > 31 {
+			problems = append(problems, "settings: month_start_day must be 1-31")
+		} else if err := validTimezone(b.Settings); err != nil {
+			problems = append(problems, fmt.Sprintf("settings: %v", err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &BackupValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// addBalanceEffect menambahkan efek satu transaksi ke perubahan saldo per akun.
+// sign -1 dipakai untuk membatalkan efek transaksi lama. Hanya akun di only yang dihitung.
+func addBalanceEffect(changes map[int64]int64, t models.Transaction, sign int64, only map[int64]bool) {
+	add := func(id, amount int64) {
+		if only[id] {
+			changes[id] += sign * amount
+		}
+	}
+	switch t.Type {
+	case "income":
+		add(t.AccountID, t.Amount)
+	case "expense":
+		add(t.AccountID, -t.Amount)
+	case "transfer":
+		add(t.AccountID, -t.Amount)
+		if t.DestinationAccountID != nil {
+			add(*t.DestinationAccountID, t.Amount)
+		}
+	}
+}
+
+// transactionMatchKey mengenali transaksi yang sama persis saat tidak ada external_id
+func transactionMatchKey(t models.Transaction) string {
+	dest := int64(0)
+	if t.DestinationAccountID != nil {
+		dest = *t.DestinationAccountID
+	}
+	return fmt.Sprintf("%d|%d|%s|%d|%s|%s|%s", t.AccountID, dest, t.Type, t.Amount,
+		t.Date.UTC().Format(time.RFC3339Nano), strings.ToLower(t.Category), t.Description)
+}
+
+// RestoreBackup mengisi database dari arsip di dalam satu transaksi DB. ID di arsip
+// dipetakan ulang ke ID baru. Akun dicocokkan berdasarkan nama, kategori berdasarkan nama,
+// budget berdasarkan (kategori, bulan, tahun), transaksi berdasarkan external_id atau isi
+// yang sama persis, transaksi berulang berdasarkan isi yang sama persis, template budget
+// berdasarkan nama dan hari libur berdasarkan tanggal.
+//
+// Saldo akun baru diambil dari arsip. Saldo akun yang sudah ada hanya berubah sebesar
+// efek transaksi yang ditambahkan atau ditimpa.
+func (s *Store) RestoreBackup(ctx context.Context, backup *models.Backup, strategy string) (*models.RestoreResult, error) {
+	switch strategy {
+	case models.RestoreSkip, models.RestoreOverwrite, models.RestoreReplace:
+	default:
+		return nil, &BackupValidationError{Problems: []string{fmt.Sprintf("unknown conflict strategy %q", strategy)}}
+	}
+	if err := validateBackup(backup); err != nil {
+		return nil, err
+	}
+	overwrite := strategy == models.RestoreOverwrite
+	result := &models.RestoreResult{Strategy: strategy}
+
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// 1. Strategi replace: kosongkan semua tabel (transaksi dulu karena foreign key ke akun).
+	// Catatan ambang budget ikut dihapus agar peringatan 80%/100% bisa muncul lagi untuk
+	// budget dari arsip; item template terhapus lewat ON DELETE CASCADE.
+	if strategy == models.RestoreReplace {
+		for _, table := range []string{"transactions", "recurring_transactions", "budgets", "budget_alerts",
+			"notifications", "budget_templates", "holidays", "categories", "accounts"} {
+			if _, err := tx.Exec(ctx, "DELETE FROM "+table); err != nil {
+				return nil, fmt.Errorf("failed to clear %s: %w", table, err)
+			}
+		}
+	}
+
+	// 2. Akun: cocokkan berdasarkan nama, lalu petakan ID arsip ke ID database
+	accountMap := make(map[int64]int64)
+	existingAccounts := make(map[int64]bool) // ID database akun yang sudah ada sebelum restore
+	for _, acc := range backup.Accounts {
+		var id int64
+		err := tx.QueryRow(ctx, `SELECT id FROM accounts WHERE LOWER(name) = LOWER($1) ORDER BY id LIMIT 1`,
+			strings.TrimSpace(acc.Name)).Scan(&id)
+		switch {
+		case err == nil:
+			existingAccounts[id] = true
+			if overwrite {
+				if _, err := tx.Exec(ctx, `UPDATE accounts SET type = $1 WHERE id = $2`, acc.Type, id); err != nil {
+					return nil, err
+				}
+				result.Accounts.Updated++
+			} else {
+				result.Accounts.Skipped++
+			}
+		case errors.Is(err, pgx.ErrNoRows):
+			createdAt := acc.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = time.Now()
+			}
+			err = tx.QueryRow(ctx,
+				`INSERT INTO accounts (name, type, current_balance, created_at) VALUES ($1, $2, $3, $4) RETURNING id`,
+				strings.TrimSpace(acc.Name), acc.Type, acc.CurrentBalance, createdAt,
+			).Scan(&id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to restore account %q: %w", acc.Name, err)
+			}
+			result.Accounts.Created++
+		default:
+			return nil, err
+		}
+		accountMap[acc.ID] = id
+	}
+
+	// 3. Kategori: nama bersifat unik, tidak ada yang perlu ditimpa
+	for _, cat := range backup.Categories {
+		ct, err := tx.Exec(ctx, `INSERT INTO categories (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`, strings.TrimSpace(cat.Name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore category %q: %w", cat.Name, err)
+		}
+		if ct.RowsAffected() > 0 {
+			result.Categories.Created++
+		} else {
+			result.Categories.Skipped++
+		}
+	}
+
+	// 4. Budget
+	budgetQuery := `
+		INSERT INTO budgets (category_name, month, year, amount, rollover, rollover_cap, period_type, period_start_day)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (category_name, month, year) DO NOTHING
+		RETURNING true`
+	if overwrite {
+		// xmax = 0 berarti baris baru, selain itu baris lama yang di-update
+		budgetQuery = `
+			INSERT INTO budgets (category_name, month, year, amount, rollover, rollover_cap, period_type, period_start_day)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (category_name, month, year)
+			DO UPDATE SET amount = EXCLUDED.amount, rollover = EXCLUDED.rollover, rollover_cap = EXCLUDED.rollover_cap,
+				period_type = EXCLUDED.period_type, period_start_day = EXCLUDED.period_start_day
+			RETURNING (xmax = 0)`
+	}
+	for _, b := range backup.Budgets {
+		var inserted bool
+		err := tx.QueryRow(ctx, budgetQuery, b.CategoryName, b.Month, b.Year, b.Amount, b.Rollover, b.RolloverCap,
+			b.PeriodType, b.PeriodStartDay).Scan(&inserted)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			result.Budgets.Skipped++
+		case err != nil:
+			return nil, fmt.Errorf("failed to restore budget %s %d/%d: %w", b.CategoryName, b.Month, b.Year, err)
+		case inserted:
+			result.Budgets.Created++
+		default:
+			result.Budgets.Updated++
+		}
+	}
+
+	// 5. Transaksi berulang (ID akun dipetakan seperti transaksi biasa)
+	for _, rt := range backup.Recurring {
+		rt.AccountID = accountMap[rt.AccountID]
+		if rt.DestinationAccountID != nil {
+			dest := accountMap[*rt.DestinationAccountID]
+			rt.DestinationAccountID = &dest
+		}
+
+		// Jadwal dianggap sama jika isi dan aturan pengulangannya sama
+		var id int64
+		err := tx.QueryRow(ctx, `
+			SELECT id FROM recurring_transactions
+			WHERE amount = $1 AND type = $2 AND category = $3 AND COALESCE(description, '') = $4
+			  AND frequency = $5 AND "interval" = $6 AND start_date = $7 AND account_id = $8
+			  AND COALESCE(rrule, '') = $9 AND business_day_shift = $10
+			LIMIT 1`,
+			rt.Amount, rt.Type, rt.Category, rt.Description, rt.Frequency, rt.Interval, rt.StartDate, rt.AccountID,
+			rt.RRule, rt.BusinessDayShift,
+		).Scan(&id)
+		switch {
+		case err == nil && overwrite:
+			_, err := tx.Exec(ctx, `
+				UPDATE recurring_transactions
+				SET destination_account_id = $1, rrule = NULLIF($2, ''), business_day_shift = $3, next_due_date = $4,
+				    end_date = $5, max_occurrences = $6, occurrences = $7, paused_at = $8
+				WHERE id = $9`,
+				rt.DestinationAccountID, rt.RRule, rt.BusinessDayShift, rt.NextDueDate,
+				rt.EndDate, rt.MaxOccurrences, rt.Occurrences, rt.PausedAt, id,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to overwrite recurring transaction %d: %w", id, err)
+			}
+			result.Recurring.Updated++
+		case err == nil:
+			result.Recurring.Skipped++
+		case errors.Is(err, pgx.ErrNoRows):
+			_, err = tx.Exec(ctx, `
+				INSERT INTO recurring_transactions
+					(amount, type, category, description, account_id, destination_account_id,
+					 frequency, "interval", rrule, business_day_shift, start_date, next_due_date, end_date,
+					 max_occurrences, occurrences, paused_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11, $12, $13, $14, $15, $16)`,
+				rt.Amount, rt.Type, rt.Category, rt.Description, rt.AccountID, rt.DestinationAccountID,
+				rt.Frequency, rt.Interval, rt.RRule, rt.BusinessDayShift, rt.StartDate, rt.NextDueDate, rt.EndDate, rt.MaxOccurrences,
+				rt.Occurrences, rt.PausedAt,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to restore recurring transaction: %w", err)
+			}
+			result.Recurring.Created++
+		default:
+			return nil, err
+		}
+	}
+
+	// 6. Transaksi: petakan ID akun, lalu cari yang sudah ada di akun lama
+	txs := make([]models.Transaction, len(backup.Transactions))
+	for i, t := range backup.Transactions {
+		t.AccountID = accountMap[t.AccountID]
+		if t.DestinationAccountID != nil {
+			dest := accountMap[*t.DestinationAccountID]
+			t.DestinationAccountID = &dest
+		}
+		txs[i] = t
+	}
+
+	byExternalID := make(map[string]models.Transaction)
+	byKey := make(map[string][]models.Transaction)
+	if len(existingAccounts) > 0 {
+		ids := make([]int64, 0, len(existingAccounts))
+		for id := range existingAccounts {
+			ids = append(ids, id)
+		}
+		rows, err := tx.Query(ctx, `
+			SELECT id, amount, type, category, COALESCE(description, ''), date,
+			       account_id, destination_account_id, external_id
+			FROM transactions WHERE account_id = ANY($1)`, ids)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var t models.Transaction
+			if err := rows.Scan(&t.ID, &t.Amount, &t.Type, &t.Category, &t.Description, &t.Date,
+				&t.AccountID, &t.DestinationAccountID, &t.ExternalID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if t.ExternalID != nil {
+				byExternalID[fmt.Sprintf("%d|%s", t.AccountID, *t.ExternalID)] = t
+			}
+			key := transactionMatchKey(t)
+			byKey[key] = append(byKey[key], t)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	balanceChanges := make(map[int64]int64)
+	toInsert := make([]models.Transaction, 0, len(txs))
+	for _, t := range txs {
+		var old *models.Transaction
+		if t.ExternalID != nil {
+			if match, ok := byExternalID[fmt.Sprintf("%d|%s", t.AccountID, *t.ExternalID)]; ok {
+				old = &match
+			}
+		}
+		if old == nil {
+			// Setiap transaksi lama hanya boleh dicocokkan satu kali
+			key := transactionMatchKey(t)
+			if matches := byKey[key]; len(matches) > 0 {
+				old = &matches[0]
+				byKey[key] = matches[1:]
+			}
+		}
+
+		switch {
+		case old == nil:
+			toInsert = append(toInsert, t)
+			addBalanceEffect(balanceChanges, t, 1, existingAccounts)
+		case overwrite:
+			_, err := tx.Exec(ctx, `
+				UPDATE transactions
+				SET amount = $1, type = $2, category = $3, description = $4, date = $5, destination_account_id = $6
+				WHERE id = $7`,
+				t.Amount, t.Type, t.Category, t.Description, t.Date, t.DestinationAccountID, old.ID,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to overwrite transaction %d: %w", old.ID, err)
+			}
+			addBalanceEffect(balanceChanges, *old, -1, existingAccounts)
+			addBalanceEffect(balanceChanges, t, 1, existingAccounts)
+			result.Transactions.Updated++
+		default:
+			result.Transactions.Skipped++
+		}
+	}
+
+	if len(toInsert) > 0 {
+		columns := []string{"amount", "type", "category", "description", "date", "created_at", "account_id", "destination_account_id", "external_id"}
+		copied, err := tx.CopyFrom(ctx, pgx.Identifier{"transactions"}, columns,
+			pgx.CopyFromSlice(len(toInsert), func(i int) ([]any, error) {
+				t := toInsert[i]
+				createdAt := t.CreatedAt
+				if createdAt.IsZero() {
+					createdAt = time.Now()
+				}
+				return []any{t.Amount, t.Type, t.Category, t.Description, t.Date, createdAt, t.AccountID, t.DestinationAccountID, t.ExternalID}, nil
+			}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy transactions: %w", err)
+		}
+		result.Transactions.Created = int(copied)
+	}
+
+	// 7. Sesuaikan saldo akun lama. Diurutkan berdasarkan ID agar urutan lock selalu sama.
+	accountIDs := make([]int64, 0, len(balanceChanges))
+	for id := range balanceChanges {
+		accountIDs = append(accountIDs, id)
+	}
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+	for _, id := range accountIDs {
+		if balanceChanges[id] == 0 {
+			continue
+		}
+		if err := updateAccountBalance(ctx, tx, id, balanceChanges[id]); err != nil {
+			return nil, fmt.Errorf("failed to update balance of account %d: %w", id, err)
+		}
+	}
+
+	// 8. Template budget: cocokkan berdasarkan nama, isi template ditimpa seluruhnya
+	for _, t := range backup.BudgetTemplates {
+		var id int64
+		err := tx.QueryRow(ctx, `SELECT id FROM budget_templates WHERE name = $1`, t.Name).Scan(&id)
+		switch {
+		case err == nil && overwrite:
+			if _, err := tx.Exec(ctx, `DELETE FROM budget_templates WHERE id = $1`, id); err != nil {
+				return nil, err
+			}
+			if err := insertTemplate(ctx, tx, &t); err != nil {
+				return nil, fmt.Errorf("failed to restore budget template %q: %w", t.Name, err)
+			}
+			result.BudgetTemplates.Updated++
+		case err == nil:
+			result.BudgetTemplates.Skipped++
+		case errors.Is(err, pgx.ErrNoRows):
+			if err := insertTemplate(ctx, tx, &t); err != nil {
+				return nil, fmt.Errorf("failed to restore budget template %q: %w", t.Name, err)
+			}
+			result.BudgetTemplates.Created++
+		default:
+			return nil, err
+		}
+	}
+
+	// 9. Hari libur
+	holidayQuery := `INSERT INTO holidays (date, name) VALUES ($1, $2) ON CONFLICT (date) DO NOTHING RETURNING true`
+	if overwrite {
+		holidayQuery = `
+			INSERT INTO holidays (date, name) VALUES ($1, $2)
+			ON CONFLICT (date) DO UPDATE SET name = EXCLUDED.name
+			RETURNING (xmax = 0)`
+	}
+	for _, h := range backup.Holidays {
+		var inserted bool
+		err := tx.QueryRow(ctx, holidayQuery, h.Date, h.Name).Scan(&inserted)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			result.Holidays.Skipped++
+		case err != nil:
+			return nil, fmt.Errorf("failed to restore holiday %s: %w", h.Date, err)
+		case inserted:
+			result.Holidays.Created++
+		default:
+			result.Holidays.Updated++
+		}
+	}
+
+	// 10. Pengaturan aplikasi selalu ada (satu baris), jadi hanya bisa ditimpa atau dilewati
+	if backup.Settings != nil {
+		if strategy == models.RestoreSkip {
+			result.Settings.Skipped++
+		} else {
+			_, err := tx.Exec(ctx, `UPDATE app_settings SET month_start_day = $1, timezone = $2, updated_at = NOW()`,
+				backup.Settings.MonthStartDay, backup.Settings.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("failed to restore settings: %w", err)
+			}
+			result.Settings.Updated++
+		}
+	}
+
+	// 11. Commit
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	if result.Settings.Updated > 0 {
+		s.cacheSettings(*backup.Settings)
+	}
+	return result, nil
+}
+
+// HandleBackup mengunduh seluruh data sebagai arsip JSON
+func (s *Store) HandleBackup(w http.ResponseWriter, r *http.Request) {
+	backup, err := s.CreateBackup(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create backup")
+		return
+	}
+
+	filename := fmt.Sprintf("finance-tracker-backup-%s.json", backup.CreatedAt.Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(backup); err != nil {
+		log.Printf("Failed to write backup: %v", err)
+	}
+}
+
+// HandleRestore memulihkan arsip dari body request (khusus admin).
+// Query param strategy: skip (default), overwrite atau replace.
+func (s *Store) HandleRestore(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	strategy := r.URL.Query().Get("strategy")
+	if strategy == "" {
+		strategy = models.RestoreSkip
+	}
+
+	var backup models.Backup
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBackupSize)).Decode(&backup); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid backup archive")
+		return
+	}
+
+	result, err := s.RestoreBackup(r.Context(), &backup, strategy)
+	if err != nil {
+		var validationErr *BackupValidationError
+		if errors.As(err, &validationErr) {
+			respondJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+				"error":  validationErr.Error(),
+				"errors": validationErr.Problems,
+			})
+			return
+		}
+		log.Printf("Restore failed: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to restore backup, no changes were made")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// elapsedDays menghitung berapa hari dari periode [start, end] yang sudah lewat per now,
+// termasuk hari ini. Periode lampau dihitung penuh, periode mendatang 0.
+func elapsedDays(start, end, now time.Time) (total, elapsed int) {
+	total = int(end.Sub(start).Hours()/24) + 1
+	switch {
+	case now.Before(start):
+		return total, 0
+	case now.After(end):
+		return total, total
+	}
+	return total, int(now.Sub(start).Hours()/24) + 1
+}
+
+// validRollover memeriksa aturan rollover dan batasnya
+func validRollover(b *models.Budget) error {
+	switch b.Rollover {
+	case "":
+		b.Rollover = models.RolloverNone
+	case models.RolloverNone, models.RolloverSurplus, models.RolloverSurplusAndDeficit:
+	case models.RolloverCapped:
+		if b.RolloverCap == nil || *b.RolloverCap < 0 {
+			return errors.New("rollover_cap is required for capped rollover and cannot be negative")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid rollover %q, use none, surplus, surplus_and_deficit or capped", b.Rollover)
+	}
+	b.RolloverCap = nil // Batas hanya berlaku untuk 'capped'
+	return nil
+}
+
+// rolloverCarry menghitung berapa dari sisa (leftover) sebuah bulan yang dibawa ke bulan berikutnya
+func rolloverCarry(rollover string, limit *int64, leftover int64) int64 {
+	switch rollover {
+	case models.RolloverSurplus:
+		return max(leftover, 0)
+	case models.RolloverSurplusAndDeficit:
+		return leftover
+	case models.RolloverCapped:
+		carry := max(leftover, 0)
+		if limit != nil {
+			carry = min(carry, *limit)
+		}
+		return carry
+	}
+	return 0
+}
+
+// categoryKey menormalkan nama kategori untuk pencocokan budget dengan transaksi
+// (sama seperti LOWER(...) di spendingForWindows), agar "Food" dan "food" dianggap sama
+func categoryKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// monthIndex mengubah bulan & tahun menjadi angka berurutan agar mudah membandingkan bulan
+func monthIndex(month, year int) int {
+	return year*12 + month - 1
+}
+
+// applyRollover mengisi CarriedOver dan Available untuk setiap budget.
+// Sisa dihitung berantai dari bulan-bulan sebelumnya: available = amount + carry bulan lalu,
+// lalu carry = aturan rollover bulan itu diterapkan pada (available - pengeluaran periodenya).
+// Rantai terputus jika ada bulan tanpa budget untuk kategori tersebut. Budget mingguan dan
+// tahunan tidak punya rollover, jadi Available = Amount.
+func (s *Store) applyRollover(ctx context.Context, budgets []models.Budget) error {
+	var categories []string
+	target := 0
+	for _, b := range budgets {
+		if chainedPeriod(b.PeriodType) {
+			categories = append(categories, categoryKey(b.CategoryName))
+			target = max(target, monthIndex(b.Month, b.Year))
+		}
+	}
+	if len(categories) > 0 {
+		if err := s.fillCarriedOver(ctx, budgets, categories, target); err != nil {
+			return err
+		}
+	}
+
+	for i := range budgets {
+		budgets[i].Available = budgets[i].Amount + budgets[i].CarriedOver
+	}
+	return nil
+}
+
+// fillCarriedOver menelusuri riwayat budget bulanan kategori-kategori ini sebelum bulan target
+func (s *Store) fillCarriedOver(ctx context.Context, budgets []models.Budget, categories []string, target int) error {
+	query := `
+		SELECT ` + budgetColumns + `
+		FROM budgets
+		WHERE LOWER(TRIM(category_name)) = ANY($1)
+			AND period_type IN ('monthly', 'custom')
+			AND (year * 12 + month - 1) < $2
+		ORDER BY year, month, id
+	`
+	rows, err := s.Pool.Query(ctx, query, categories, target)
+	if err != nil {
+		return err
+	}
+	history, err := scanBudgets(rows)
+	if err != nil {
+		return err
+	}
+
+	cal := calendarFrom(ctx)
+	windows := make([]spendWindow, len(history))
+	for i, b := range history {
+		start, end := cal.periodWindow(b.PeriodType, b.PeriodStartDay, b.Month, b.Year, time.Time{})
+		windows[i] = spendWindow{Category: b.CategoryName, Start: start, End: end}
+	}
+	spent, err := s.spendingForWindows(ctx, windows, 0)
+	if err != nil {
+		return err
+	}
+
+	// carries[kategori][bulan] = sisa yang dibawa keluar dari bulan tersebut
+	carries := make(map[string]map[int]int64)
+	for i, b := range history {
+		idx := monthIndex(b.Month, b.Year)
+		key := categoryKey(b.CategoryName)
+		chain, ok := carries[key]
+		if !ok {
+			chain = make(map[int]int64)
+			carries[key] = chain
+		}
+		available := b.Amount + chain[idx-1] // 0 jika bulan sebelumnya tidak ada budget
+		chain[idx] = rolloverCarry(b.Rollover, b.RolloverCap, available-spent[i])
+	}
+
+	for i := range budgets {
+		b := &budgets[i]
+		if chainedPeriod(b.PeriodType) {
+			b.CarriedOver = carries[categoryKey(b.CategoryName)][monthIndex(b.Month, b.Year)-1]
+		}
+	}
+	return nil
+}
+
+// buildBudgetProgress menghitung realisasi dari limit dan pengeluaran sebuah kategori
+func buildBudgetProgress(category string, limit, spent int64, totalDays, elapsed int) models.BudgetProgress {
+	p := models.BudgetProgress{
+		CategoryName:   category,
+		Limit:          limit,
+		Spent:          spent,
+		Remaining:      limit - spent,
+		ProjectedSpend: spent,
+	}
+	if limit > 0 {
+		p.PercentUsed = math.Round(float64(spent)*10000/float64(limit)) / 100
+	}
+	if elapsed > 0 {
+		p.DailyBurnRate = spent / int64(elapsed)
+		p.ProjectedSpend = spent * int64(totalDays) / int64(elapsed)
+	}
+	return p
+}
+
+// GetBudgetProgress menggabungkan budget bulan tertentu dengan pengeluaran kategorinya
+// selama periode budget tersebut. Nama kategori dicocokkan tanpa membedakan huruf
+// besar/kecil; pengeluaran bulan itu yang tanpa budget masuk ke bucket Unbudgeted.
+func (s *Store) GetBudgetProgress(ctx context.Context, month, year int) (*models.BudgetProgressResponse, error) {
+	now := time.Now()
+	start, end := calendarFrom(ctx).monthRange(month, year)
+	totalDays, elapsed := elapsedDays(start, end, now)
+
+	budgets, err := s.GetBudgets(ctx, month, year)
+	if err != nil {
+		return nil, err
+	}
+	spending, err := s.GetCategorySummary(ctx, start, end, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.BudgetProgressResponse{
+		Month:       month,
+		Year:        year,
+		DaysInMonth: totalDays,
+		DaysElapsed: elapsed,
+		Categories:  make([]models.BudgetProgress, 0, len(budgets)),
+		Unbudgeted:  models.UnbudgetedSpending{Categories: make([]models.CategorySummary, 0)},
+	}
+
+	// Pengeluaran budget dihitung per periode masing-masing (minggu, siklus gajian, tahun)
+	windows := make([]spendWindow, len(budgets))
+	for i, b := range budgets {
+		windows[i] = spendWindow{Category: b.CategoryName, Start: b.PeriodStart, End: b.PeriodEnd}
+	}
+	spent, err := s.spendingForWindows(ctx, windows, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	budgeted := make(map[string]bool)
+	for i, b := range budgets {
+		budgeted[categoryKey(b.CategoryName)] = true
+		periodDays, periodElapsed := elapsedDays(b.PeriodStart, b.PeriodEnd, now)
+		progress := buildBudgetProgress(b.CategoryName, b.Available, spent[i], periodDays, periodElapsed)
+		progress.CarriedOver = b.CarriedOver
+		progress.PeriodType = b.PeriodType
+		progress.PeriodStart, progress.PeriodEnd = b.PeriodStart, b.PeriodEnd
+		progress.DaysInPeriod, progress.DaysElapsed = periodDays, periodElapsed
+		response.Categories = append(response.Categories, progress)
+	}
+
+	// GetCategorySummary sudah terurut dari pengeluaran terbesar
+	for _, cs := range spending {
+		if budgeted[categoryKey(cs.Category)] {
+			continue
+		}
+		response.Unbudgeted.Spent += cs.TotalAmount
+		response.Unbudgeted.Categories = append(response.Unbudgeted.Categories, cs)
+	}
+
+	return response, nil
+}
+
+// HandleGetBudgetProgress menangani GET /api/budgets/progress?month=&year=
+func (s *Store) HandleGetBudgetProgress(w http.ResponseWriter, r *http.Request) {
+	month, year := parseMonthYear(r)
+
+	progress, err := s.GetBudgetProgress(r.Context(), month, year)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, progress)
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/exporter"
+	"github.com/bramszs/finance-tracker/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// budgetAlertThresholds adalah ambang (persen dari budget) yang memicu notifikasi
+var budgetAlertThresholds = []int{80, 100}
+
+// crossedThresholds mengembalikan ambang yang sudah terlewati. Budget nol atau minus
+// (karena rollover kekurangan) dianggap terlewati begitu ada pengeluaran.
+func crossedThresholds(spent, limit int64) []int {
+	var crossed []int
+	if spent <= 0 {
+		return crossed
+	}
+	for _, t := range budgetAlertThresholds {
+		if spent*100 >= limit*int64(t) {
+			crossed = append(crossed, t)
+		}
+	}
+	return crossed
+}
+
+func budgetAlertNotification(b models.Budget, alert models.BudgetAlert) (models.Notification, error) {
+	n := models.Notification{Type: models.NotificationBudgetAlert}
+	if alert.Threshold >= 100 {
+		n.Title = fmt.Sprintf("Budget %s exceeded", alert.CategoryName)
+	} else {
+		n.Title = fmt.Sprintf("Budget %s %d%% used", alert.CategoryName, alert.Threshold)
+	}
+	n.Message = fmt.Sprintf("Spending in %s for %s is %s of %s.",
+		alert.CategoryName, periodLabel(b), exporter.FormatRupiah(alert.Spent), exporter.FormatRupiah(alert.Limit))
+
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return n, err
+	}
+	n.Data = data
+	return n, nil
+}
+
+// checkBudgetAlerts dipanggil setelah transaksi tersimpan. Transaksinya sudah di-commit,
+// jadi kegagalan di sini hanya dicatat di log.
+func (s *Store) checkBudgetAlerts(ctx context.Context, txData models.Transaction) {
+	if txData.Type != "expense" {
+		return
+	}
+	if _, err := s.EvaluateBudgetAlerts(ctx, txData.Category, txData.Date); err != nil {
+		log.Printf("Failed to evaluate budget alerts for %q: %v", txData.Category, err)
+	}
+}
+
+// checkBudgetAlertsBatch menjalankan checkBudgetAlerts untuk transaksi yang disimpan
+// sekaligus (batch & import). Ambang dihitung dari total periode, jadi cukup satu
+// pengecekan per kategori & tanggal.
+func (s *Store) checkBudgetAlertsBatch(ctx context.Context, txs []models.Transaction) {
+	loc := calendarFrom(ctx).loc
+	checked := make(map[string]bool)
+	for _, t := range txs {
+		key := categoryKey(t.Category) + "|" + dateKey(t.Date.In(loc))
+		if t.Type != "expense" || checked[key] {
+			continue
+		}
+		checked[key] = true
+		s.checkBudgetAlerts(ctx, t)
+	}
+}
+
+// budgetsCovering mengambil budget sebuah kategori yang periodenya memuat date:
+// budget bulan itu, siklus custom bulan lalu, atau budget tahunan yang masih berjalan
+func (s *Store) budgetsCovering(ctx context.Context, category string, date time.Time) ([]models.Budget, error) {
+	cal := calendarFrom(ctx)
+	idx := monthIndex(cal.monthOf(date))
+	query := `
+		SELECT ` + budgetColumns + `
+		FROM budgets
+		WHERE LOWER(category_name) = LOWER($1)
+			AND (year * 12 + month - 1) BETWEEN $2 - 11 AND $2
+	`
+	rows, err := s.Pool.Query(ctx, query, category, idx)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := scanBudgets(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var budgets []models.Budget
+	for _, b := range candidates {
+		if b.PeriodType == models.PeriodWeekly && monthIndex(b.Month, b.Year) != idx {
+			continue // Budget mingguan hanya berlaku untuk minggu-minggu di bulannya
+		}
+		cal.setBudgetWindow(&b, date)
+		if !date.Before(b.PeriodStart) && !date.After(b.PeriodEnd) {
+			budgets = append(budgets, b)
+		}
+	}
+	if err := s.applyRollover(ctx, budgets); err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+// EvaluateBudgetAlerts membandingkan pengeluaran sebuah kategori dengan setiap budget yang
+// periodenya memuat date (termasuk rollover). Setiap ambang yang baru terlewati dicatat
+// sekali per periode di budget_alerts, disimpan sebagai notifikasi in-app, lalu dikirim
+// ke channel lain.
+func (s *Store) EvaluateBudgetAlerts(ctx context.Context, category string, date time.Time) ([]models.Notification, error) {
+	budgets, err := s.budgetsCovering(ctx, category, date)
+	if err != nil || len(budgets) == 0 {
+		return nil, err // Kategori tanpa budget tidak punya ambang
+	}
+
+	windows := make([]spendWindow, len(budgets))
+	for i, b := range budgets {
+		windows[i] = spendWindow{Category: b.CategoryName, Start: b.PeriodStart, End: b.PeriodEnd}
+	}
+	spent, err := s.spendingForWindows(ctx, windows, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var notifications []models.Notification
+	for i, b := range budgets {
+		for _, threshold := range crossedThresholds(spent[i], b.Available) {
+			alert := models.BudgetAlert{
+				CategoryName: b.CategoryName,
+				Month:        b.Month,
+				Year:         b.Year,
+				PeriodType:   b.PeriodType,
+				PeriodStart:  b.PeriodStart,
+				Threshold:    threshold,
+				Spent:        spent[i],
+				Limit:        b.Available,
+			}
+			err := tx.QueryRow(ctx, `
+				INSERT INTO budget_alerts (category_name, month, year, period_type, period_start, threshold, spent, budget_limit)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+				ON CONFLICT (category_name, period_type, period_start, threshold) DO NOTHING
+				RETURNING id, created_at`,
+				alert.CategoryName, alert.Month, alert.Year, alert.PeriodType, alert.PeriodStart.Format("2006-01-02"),
+				alert.Threshold, alert.Spent, alert.Limit,
+			).Scan(&alert.ID, &alert.CreatedAt)
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue // Ambang ini sudah pernah dicatat untuk periode ini
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			n, err := budgetAlertNotification(b, alert)
+			if err != nil {
+				return nil, err
+			}
+			if err := insertNotification(ctx, tx, &n); err != nil {
+				return nil, err
+			}
+			notifications = append(notifications, n)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	for _, n := range notifications {
+		s.Notifier.Dispatch(n)
+	}
+	return notifications, nil
+}
+
+func (s *Store) GetBudgetAlerts(ctx context.Context, month, year int) ([]models.BudgetAlert, error) {
+	rows, err := s.Pool.Query(ctx, `
+		SELECT id, category_name, month, year, period_type, period_start, threshold, spent, budget_limit, created_at
+		FROM budget_alerts WHERE month = $1 AND year = $2
+		ORDER BY created_at, threshold`, month, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := make([]models.BudgetAlert, 0)
+	for rows.Next() {
+		var a models.BudgetAlert
+		if err := rows.Scan(&a.ID, &a.CategoryName, &a.Month, &a.Year, &a.PeriodType, &a.PeriodStart, &a.Threshold, &a.Spent, &a.Limit, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
+// HandleGetBudgetAlerts menangani GET /api/budgets/alerts?month=&year=
+func (s *Store) HandleGetBudgetAlerts(w http.ResponseWriter, r *http.Request) {
+	month, year := parseMonthYear(r)
+
+	alerts, err := s.GetBudgetAlerts(r.Context(), month, year)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, alerts)
+}
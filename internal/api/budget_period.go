@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// validPeriod memeriksa jenis periode budget dan hari mulainya. Harus dipanggil
+// setelah validRollover, karena rollover hanya berlaku untuk periode bulanan.
+func validPeriod(b *models.Budget) error {
+	switch b.PeriodType {
+	case "", models.PeriodMonthly, models.PeriodYearly:
+		if b.PeriodType == "" {
+			b.PeriodType = models.PeriodMonthly
+		}
+		b.PeriodStartDay = nil
+	case models.PeriodCustom:
+		if b.PeriodStartDay == nil || *b.PeriodStartDay < 1 || *b.PeriodStartDay > 31 {
+			return errors.New("period_start_day must be 1-31 for custom periods")
+		}
+	case models.PeriodWeekly:
+		if b.PeriodStartDay == nil {
+			monday := 1
+			b.PeriodStartDay = &monday
+		} else if *b.PeriodStartDay < 1 || *b.PeriodStartDay > 7 {
+			return errors.New("period_start_day must be 1 (Monday) to 7 (Sunday) for weekly periods")
+		}
+	default:
+		return fmt.Errorf("invalid period_type %q, use weekly, monthly, custom or yearly", b.PeriodType)
+	}
+
+	if b.Rollover != models.RolloverNone && !chainedPeriod(b.PeriodType) {
+		return errors.New("rollover is only supported for monthly and custom periods")
+	}
+	return nil
+}
+
+// ErrBudgetOverlap: budget tahunan tidak boleh bertumpuk dengan budget lain kategori yang sama,
+// karena pengeluarannya akan terhitung dua kali
+var ErrBudgetOverlap = errors.New("a yearly budget cannot overlap another budget of the same category")
+
+// checkBudgetOverlap memastikan budget kategori di bulan (month, year) yang baru ditulis tidak
+// bertumpuk dengan budget tahunan: tidak ada budget tahunan lain yang mencakup bulan itu, dan
+// jika budget itu sendiri tahunan, tidak ada budget lain di 12 bulan periodenya. Dipanggil
+// setelah menulis di dalam transaksi yang sama, agar item dalam satu permintaan ikut dicek.
+func checkBudgetOverlap(ctx context.Context, tx pgx.Tx, category string, month, year int) error {
+	var conflict string
+	err := tx.QueryRow(ctx, `
+		SELECT other.period_type || ' ' || other.month || '/' || other.year
+		FROM budgets b
+		JOIN budgets other ON LOWER(TRIM(other.category_name)) = LOWER(TRIM(b.category_name)) AND other.id <> b.id
+		WHERE b.category_name = $1 AND b.month = $2 AND b.year = $3
+			AND (
+				(other.period_type = 'yearly'
+					AND (other.year * 12 + other.month - 1) BETWEEN (b.year * 12 + b.month - 1) - 11 AND (b.year * 12 + b.month - 1))
+				OR (b.period_type = 'yearly'
+					AND (other.year * 12 + other.month - 1) BETWEEN (b.year * 12 + b.month - 1) AND (b.year * 12 + b.month - 1) + 11)
+			)
+		LIMIT 1`, category, month, year).Scan(&conflict)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s %d/%d overlaps the %s budget", ErrBudgetOverlap, category, month, year, conflict)
+}
+
+// chainedPeriod menandai periode yang bersambung dari bulan ke bulan (bisa rollover)
+func chainedPeriod(periodType string) bool {
+	return periodType == models.PeriodMonthly || periodType == models.PeriodCustom
+}
+
+// periodWindow mengembalikan awal dan akhir (inklusif) periode budget yang berawal di
+// bulan keuangan (month, year). Untuk budget mingguan, yang dikembalikan adalah minggu yang memuat ref.
+func (c calendar) periodWindow(periodType string, startDay *int, month, year int, ref time.Time) (time.Time, time.Time) {
+	switch periodType {
+	case models.PeriodCustom:
+		day := 1
+		if startDay != nil {
+			day = *startDay
+		}
+		start := cycleStart(month, year, day, c.loc)
+		return start, cycleStart(month+1, year, day, c.loc).Add(-time.Nanosecond)
+	case models.PeriodYearly:
+		start, _ := c.monthRange(month, year)
+		_, end := c.monthRange(month+11, year)
+		return start, end
+	case models.PeriodWeekly:
+		weekStart := 1
+		if startDay != nil {
+			weekStart = *startDay
+		}
+		ref = ref.In(c.loc)
+		isoDay := (int(ref.Weekday())+6)%7 + 1 // 1 = Senin ... 7 = Minggu
+		offset := (isoDay - weekStart + 7) % 7
+		start := time.Date(ref.Year(), ref.Month(), ref.Day()-offset, 0, 0, 0, 0, c.loc)
+		return start, start.AddDate(0, 0, 7).Add(-time.Nanosecond)
+	}
+	return c.monthRange(month, year)
+}
+
+// setBudgetWindow mengisi PeriodStart dan PeriodEnd sebuah budget. Untuk budget mingguan,
+// ref dibatasi ke dalam bulan budget tersebut.
+func (c calendar) setBudgetWindow(b *models.Budget, ref time.Time) {
+	ref = c.clampToMonth(ref, b.Month, b.Year)
+	b.PeriodStart, b.PeriodEnd = c.periodWindow(b.PeriodType, b.PeriodStartDay, b.Month, b.Year, ref)
+}
+
+// periodLabel menulis periode budget untuk pesan notifikasi
+func periodLabel(b models.Budget) string {
+	if b.PeriodType == models.PeriodMonthly {
+		return fmt.Sprintf("%s %d", time.Month(b.Month), b.Year)
+	}
+	return fmt.Sprintf("%s - %s", b.PeriodStart.Format("2 Jan 2006"), b.PeriodEnd.Format("2 Jan 2006"))
+}
+
+// spendWindow adalah satu rentang tanggal yang pengeluaran kategorinya ingin dijumlahkan
+type spendWindow struct {
+	Category string
+	Start    time.Time
+	End      time.Time
+}
+
+// spendingForWindows menjumlahkan pengeluaran setiap window dalam satu query. Nama kategori
+// dicocokkan tanpa membedakan huruf besar/kecil; accountID 0 berarti semua akun.
+func (s *Store) spendingForWindows(ctx context.Context, windows []spendWindow, accountID int64) ([]int64, error) {
+	totals := make([]int64, len(windows))
+	if len(windows) == 0 {
+		return totals, nil
+	}
+
+	categories := make([]string, len(windows))
+	starts := make([]time.Time, len(windows))
+	ends := make([]time.Time, len(windows))
+	for i, w := range windows {
+		categories[i], starts[i], ends[i] = w.Category, w.Start, w.End
+	}
+
+	accountFilter := ""
+	args := []interface{}{categories, starts, ends}
+	if accountID > 0 {
+		accountFilter = " AND t.account_id = $4"
+		args = append(args, accountID)
+	}
+
+	rows, err := s.Pool.Query(ctx, `
+		SELECT w.idx, COALESCE(SUM(t.amount), 0)
+		FROM unnest($1::text[], $2::timestamptz[], $3::timestamptz[])
+			WITH ORDINALITY AS w(category, start_at, end_at, idx)
+		LEFT JOIN transactions t ON
+			t.type = 'expense'
+			AND LOWER(t.category) = LOWER(w.category)
+			AND t.date >= w.start_at
+			AND t.date <= w.end_at`+accountFilter+`
+		GROUP BY w.idx`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var idx, total int64
+		if err := rows.Scan(&idx, &total); err != nil {
+			return nil, err
+		}
+		totals[idx-1] = total
+	}
+	return totals, rows.Err()
+}
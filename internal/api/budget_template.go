@@ -0,0 +1,591 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// maxBudgetMonths adalah rentang bulan terpanjang untuk copy dan apply template (10 tahun)
+const maxBudgetMonths = 120
+
+// ErrTemplateNotFound dikembalikan jika template budget tidak ada
+var ErrTemplateNotFound = errors.New("budget template not found")
+
+// ErrNoBudgetsFound dikembalikan jika bulan sumber untuk copy atau template tidak punya budget
+var ErrNoBudgetsFound = errors.New("no budgets found")
+
+// validateBudgetItems memeriksa setiap item dan menolak kategori yang muncul dua kali
+func validateBudgetItems(items []models.BudgetItem) error {
+	seen := make(map[string]bool)
+	for i := range items {
+		item := &items[i]
+		item.CategoryName = strings.TrimSpace(item.CategoryName)
+		if item.CategoryName == "" || len(item.CategoryName) > 50 {
+			return fmt.Errorf("budgets[%d]: category_name must be 1-50 characters", i)
+		}
+		if item.Amount < 0 {
+			return fmt.Errorf("budgets[%d]: amount cannot be negative", i)
+		}
+		key := categoryKey(item.CategoryName)
+		if seen[key] {
+			return fmt.Errorf("budgets[%d]: category %q appears more than once", i, item.CategoryName)
+		}
+		seen[key] = true
+
+		b := models.Budget{Rollover: item.Rollover, RolloverCap: item.RolloverCap, PeriodType: item.PeriodType, PeriodStartDay: item.PeriodStartDay}
+		if err := validRollover(&b); err != nil {
+			return fmt.Errorf("budgets[%d]: %v", i, err)
+		}
+		if err := validPeriod(&b); err != nil {
+			return fmt.Errorf("budgets[%d]: %v", i, err)
+		}
+		item.Rollover, item.RolloverCap = b.Rollover, b.RolloverCap
+		item.PeriodType, item.PeriodStartDay = b.PeriodType, b.PeriodStartDay
+	}
+	return nil
+}
+
+// monthSpan mengembalikan indeks bulan dari (month, year) sampai (endMonth, endYear).
+// endMonth 0 berarti hanya satu bulan.
+func monthSpan(month, year, endMonth, endYear int) ([]int, error) {
+	if month < 1 || month > 12 || year < 1 {
+		return nil, errors.New("invalid target month or year")
+	}
+	first := monthIndex(month, year)
+	last := first
+	if endMonth != 0 || endYear != 0 {
+		if endMonth < 1 || endMonth > 12 || endYear < 1 {
+			return nil, errors.New("invalid end month or year")
+		}
+		last = monthIndex(endMonth, endYear)
+	}
+	if last < first {
+		return nil, errors.New("end month is before the start month")
+	}
+	if last-first+1 > maxBudgetMonths {
+		return nil, fmt.Errorf("range cannot exceed %d months", maxBudgetMonths)
+	}
+
+	months := make([]int, 0, last-first+1)
+	for idx := first; idx <= last; idx++ {
+		months = append(months, idx)
+	}
+	return months, nil
+}
+
+// upsertBudgetItems menulis item ke setiap bulan. Budget yang sudah ada dilewati,
+// kecuali overwrite bernilai true.
+func upsertBudgetItems(ctx context.Context, tx pgx.Tx, items []models.BudgetItem, months []int, overwrite bool, result *models.BudgetCopyResult) error {
+	query := `
+		INSERT INTO budgets (category_name, month, year, amount, rollover, rollover_cap, period_type, period_start_day)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (category_name, month, year) DO NOTHING
+		RETURNING true`
+	if overwrite {
+		// xmax = 0 berarti baris baru, selain itu baris lama yang di-update
+		query = `
+			INSERT INTO budgets (category_name, month, year, amount, rollover, rollover_cap, period_type, period_start_day)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (category_name, month, year)
+			DO UPDATE SET amount = EXCLUDED.amount, rollover = EXCLUDED.rollover, rollover_cap = EXCLUDED.rollover_cap,
+				period_type = EXCLUDED.period_type, period_start_day = EXCLUDED.period_start_day
+			RETURNING (xmax = 0)`
+	}
+
+	for _, idx := range months {
+		month, year := idx%12+1, idx/12
+		for _, item := range items {
+			var inserted bool
+			err := tx.QueryRow(ctx, query, item.CategoryName, month, year, item.Amount, item.Rollover, item.RolloverCap,
+				item.PeriodType, item.PeriodStartDay).Scan(&inserted)
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+				result.Skipped++
+				continue
+			case err != nil:
+				return fmt.Errorf("failed to set budget %s %d/%d: %w", item.CategoryName, month, year, err)
+			case inserted:
+				result.Created++
+			default:
+				result.Updated++
+			}
+			if err := checkBudgetOverlap(ctx, tx, item.CategoryName, month, year); err != nil {
+				return err
+			}
+		}
+	}
+	result.Months = len(months)
+	return nil
+}
+
+// SetMonthBudgets menyimpan semua budget satu bulan sekaligus di dalam satu transaksi.
+// Jika replace bernilai true, budget kategori yang tidak ada di items dihapus.
+func (s *Store) SetMonthBudgets(ctx context.Context, month, year int, items []models.BudgetItem, replace bool) ([]models.Budget, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Pakai ejaan nama kategori yang sudah tersimpan, agar "makanan" menimpa budget "Makanan"
+	// dan tidak menjadi baris kedua
+	rows, err := tx.Query(ctx, `SELECT category_name FROM budgets WHERE month = $1 AND year = $2`, month, year)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]string)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stored[categoryKey(name)] = name
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if name, ok := stored[categoryKey(items[i].CategoryName)]; ok {
+			items[i].CategoryName = name
+		}
+	}
+
+	if replace {
+		// Nama kategori dibandingkan tanpa membedakan huruf besar/kecil, seperti categoryKey
+		categories := make([]string, len(items))
+		for i, item := range items {
+			categories[i] = categoryKey(item.CategoryName)
+		}
+		_, err := tx.Exec(ctx, `
+			DELETE FROM budgets
+			WHERE month = $1 AND year = $2 AND NOT (LOWER(TRIM(category_name)) = ANY($3))`,
+			month, year, categories)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result models.BudgetCopyResult
+	if err := upsertBudgetItems(ctx, tx, items, []int{monthIndex(month, year)}, true, &result); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return s.GetBudgets(ctx, month, year)
+}
+
+// budgetItemsForMonth mengambil budget satu bulan sebagai item tanpa bulan
+func budgetItemsForMonth(ctx context.Context, tx pgx.Tx, month, year int) ([]models.BudgetItem, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT category_name, amount, rollover, rollover_cap, period_type, period_start_day
+		FROM budgets WHERE month = $1 AND year = $2
+		ORDER BY category_name`, month, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]models.BudgetItem, 0)
+	for rows.Next() {
+		var item models.BudgetItem
+		if err := rows.Scan(&item.CategoryName, &item.Amount, &item.Rollover, &item.RolloverCap, &item.PeriodType, &item.PeriodStartDay); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// CopyBudgets menyalin budget satu bulan (termasuk aturan rollover) ke bulan atau rentang bulan lain
+func (s *Store) CopyBudgets(ctx context.Context, req models.BudgetCopyRequest) (*models.BudgetCopyResult, error) {
+	months, err := monthSpan(req.ToMonth, req.ToYear, req.ToEndMonth, req.ToEndYear)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	items, err := budgetItemsForMonth(ctx, tx, req.FromMonth, req.FromYear)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w for %d/%d", ErrNoBudgetsFound, req.FromMonth, req.FromYear)
+	}
+
+	result := &models.BudgetCopyResult{}
+	if err := upsertBudgetItems(ctx, tx, items, months, req.Overwrite, result); err != nil {
+		return nil, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// loadTemplateItems mengisi Items untuk template yang sudah dibaca
+func loadTemplateItems(ctx context.Context, q interface {
+	Query(context.Context, string, ...any) (pgx.Rows, error)
+}, templates []models.BudgetTemplate) error {
+	if len(templates) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(templates))
+	byID := make(map[int64]*models.BudgetTemplate)
+	for i := range templates {
+		ids[i] = templates[i].ID
+		templates[i].Items = make([]models.BudgetItem, 0)
+		byID[templates[i].ID] = &templates[i]
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT template_id, category_name, amount, rollover, rollover_cap, period_type, period_start_day
+		FROM budget_template_items WHERE template_id = ANY($1)
+		ORDER BY category_name`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var templateID int64
+		var item models.BudgetItem
+		if err := rows.Scan(&templateID, &item.CategoryName, &item.Amount, &item.Rollover, &item.RolloverCap,
+			&item.PeriodType, &item.PeriodStartDay); err != nil {
+			return err
+		}
+		t := byID[templateID]
+		t.Items = append(t.Items, item)
+	}
+	return rows.Err()
+}
+
+// GetBudgetTemplates mengambil semua template beserta isinya
+func (s *Store) GetBudgetTemplates(ctx context.Context) ([]models.BudgetTemplate, error) {
+	rows, err := s.Pool.Query(ctx, `SELECT id, name, created_at FROM budget_templates ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := make([]models.BudgetTemplate, 0)
+	for rows.Next() {
+		var t models.BudgetTemplate
+		if err := rows.Scan(&t.ID, &t.Name, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := loadTemplateItems(ctx, s.Pool, templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (s *Store) GetBudgetTemplate(ctx context.Context, id int64) (*models.BudgetTemplate, error) {
+	var t models.BudgetTemplate
+	err := s.Pool.QueryRow(ctx, `SELECT id, name, created_at FROM budget_templates WHERE id = $1`, id).
+		Scan(&t.ID, &t.Name, &t.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []models.BudgetTemplate{t}
+	if err := loadTemplateItems(ctx, s.Pool, templates); err != nil {
+		return nil, err
+	}
+	return &templates[0], nil
+}
+
+// insertTemplate menyimpan template dan semua itemnya di dalam transaksi yang sedang berjalan
+func insertTemplate(ctx context.Context, tx pgx.Tx, t *models.BudgetTemplate) error {
+	err := tx.QueryRow(ctx, `INSERT INTO budget_templates (name) VALUES ($1) RETURNING id, created_at`, t.Name).
+		Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return err
+	}
+	for _, item := range t.Items {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO budget_template_items (template_id, category_name, amount, rollover, rollover_cap, period_type, period_start_day)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			t.ID, item.CategoryName, item.Amount, item.Rollover, item.RolloverCap, item.PeriodType, item.PeriodStartDay)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateBudgetTemplate membuat template dari req.Items, atau dari budget bulan
+// req.FromMonth/req.FromYear jika Items kosong
+func (s *Store) CreateBudgetTemplate(ctx context.Context, req models.BudgetTemplateRequest) (*models.BudgetTemplate, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	t := &models.BudgetTemplate{Name: req.Name, Items: req.Items}
+	if len(t.Items) == 0 {
+		t.Items, err = budgetItemsForMonth(ctx, tx, req.FromMonth, req.FromYear)
+		if err != nil {
+			return nil, err
+		}
+		if len(t.Items) == 0 {
+			return nil, fmt.Errorf("%w for %d/%d", ErrNoBudgetsFound, req.FromMonth, req.FromYear)
+		}
+	}
+
+	if err := insertTemplate(ctx, tx, t); err != nil {
+		return nil, err
+	}
+	return t, tx.Commit(ctx)
+}
+
+func (s *Store) DeleteBudgetTemplate(ctx context.Context, id int64) error {
+	ct, err := s.Pool.Exec(ctx, `DELETE FROM budget_templates WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// ApplyBudgetTemplate menulis isi template ke satu bulan atau rentang bulan
+func (s *Store) ApplyBudgetTemplate(ctx context.Context, id int64, req models.ApplyTemplateRequest) (*models.BudgetCopyResult, error) {
+	months, err := monthSpan(req.Month, req.Year, req.EndMonth, req.EndYear)
+	if err != nil {
+		return nil, err
+	}
+	template, err := s.GetBudgetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	result := &models.BudgetCopyResult{}
+	if err := upsertBudgetItems(ctx, tx, template.Items, months, req.Overwrite, result); err != nil {
+		return nil, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// HandleSetMonthBudgets menangani PUT /api/budgets/{year}/{month}
+func (s *Store) HandleSetMonthBudgets(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	year, _ := strconv.Atoi(vars["year"])
+	month, _ := strconv.Atoi(vars["month"])
+	if month < 1 || month > 12 || year < 1 {
+		respondError(w, http.StatusBadRequest, "Invalid month or year")
+		return
+	}
+
+	var req models.BulkBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if err := validateBudgetItems(req.Budgets); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	budgets, err := s.SetMonthBudgets(r.Context(), month, year, req.Budgets, req.Replace)
+	if errors.Is(err, ErrBudgetOverlap) {
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, budgets)
+}
+
+// HandleCopyBudgets menangani POST /api/budgets/copy
+func (s *Store) HandleCopyBudgets(w http.ResponseWriter, r *http.Request) {
+	var req models.BudgetCopyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if req.FromMonth < 1 || req.FromMonth > 12 || req.FromYear < 1 {
+		respondError(w, http.StatusBadRequest, "Invalid source month or year")
+		return
+	}
+	if _, err := monthSpan(req.ToMonth, req.ToYear, req.ToEndMonth, req.ToEndYear); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := s.CopyBudgets(r.Context(), req)
+	if errors.Is(err, ErrBudgetOverlap) {
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if errors.Is(err, ErrNoBudgetsFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// HandleGetBudgetTemplates menangani GET /api/budgets/templates
+func (s *Store) HandleGetBudgetTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := s.GetBudgetTemplates(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, templates)
+}
+
+// HandleGetBudgetTemplate menangani GET /api/budgets/templates/{id}
+func (s *Store) HandleGetBudgetTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromVars(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	template, err := s.GetBudgetTemplate(r.Context(), id)
+	if errors.Is(err, ErrTemplateNotFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, template)
+}
+
+// HandleCreateBudgetTemplate menangani POST /api/budgets/templates
+func (s *Store) HandleCreateBudgetTemplate(w http.ResponseWriter, r *http.Request) {
+	var req models.BudgetTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		respondError(w, http.StatusBadRequest, "Template name must be 1-100 characters")
+		return
+	}
+	if len(req.Items) == 0 && (req.FromMonth < 1 || req.FromMonth > 12 || req.FromYear < 1) {
+		respondError(w, http.StatusBadRequest, "Provide items or from_month and from_year")
+		return
+	}
+	if err := validateBudgetItems(req.Items); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	template, err := s.CreateBudgetTemplate(r.Context(), req)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			respondError(w, http.StatusConflict, "A template with this name already exists")
+			return
+		}
+		if errors.Is(err, ErrNoBudgetsFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, template)
+}
+
+// HandleDeleteBudgetTemplate menangani DELETE /api/budgets/templates/{id}
+func (s *Store) HandleDeleteBudgetTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromVars(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.DeleteBudgetTemplate(r.Context(), id); err != nil {
+		if errors.Is(err, ErrTemplateNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleApplyBudgetTemplate menangani POST /api/budgets/templates/{id}/apply
+func (s *Store) HandleApplyBudgetTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromVars(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req models.ApplyTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if _, err := monthSpan(req.Month, req.Year, req.EndMonth, req.EndYear); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := s.ApplyBudgetTemplate(r.Context(), id, req)
+	if err != nil {
+		if errors.Is(err, ErrTemplateNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, ErrBudgetOverlap) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
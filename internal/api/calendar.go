@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// calendar menentukan batas bulan keuangan aplikasi. Bulan keuangan (month, year) dimulai
+// tanggal monthStartDay di bulan tersebut dan berakhir sehari sebelum tanggal yang sama
+// di bulan berikutnya; monthStartDay 1 berarti bulan kalender biasa.
+type calendar struct {
+	loc           *time.Location
+	monthStartDay int
+}
+
+// locations menyimpan *time.Location yang sudah dimuat, per nama zona waktu
+var locations sync.Map
+
+// loadLocation memuat zona waktu; nama yang tidak dikenal diganti zona waktu default
+func loadLocation(name string) *time.Location {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("Unknown timezone %q, using %s: %v", name, models.DefaultTimezone, err)
+		if loc, err = time.LoadLocation(models.DefaultTimezone); err != nil {
+			loc = time.UTC
+		}
+	}
+	locations.Store(name, loc)
+	return loc
+}
+
+// calendarFrom menyusun calendar dari pengaturan aplikasi di context request
+func calendarFrom(ctx context.Context) calendar {
+	settings := settingsFrom(ctx)
+	return calendar{loc: loadLocation(settings.Timezone), monthStartDay: settings.MonthStartDay}
+}
+
+// today mengembalikan tanggal hari ini (jam 00:00) di zona waktu aplikasi
+func (c calendar) today() time.Time {
+	now := time.Now().In(c.loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, c.loc)
+}
+
+// atDate memindahkan tanggal kalender t (misal kolom DATE yang dibaca sebagai UTC, atau
+// tanggal dari file bank) ke jam 00:00 di zona waktu aplikasi
+func (c calendar) atDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc)
+}
+
+// parseTime membaca tanggal dari input pengguna. Format dengan offset (RFC 3339) dipakai
+// apa adanya; "2006-01-02", "2006-01-02 15:04" dan "2006-01-02T15:04:05" tanpa offset
+// dibaca di zona waktu aplikasi.
+func (c calendar) parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.In(c.loc), nil
+	}
+	var err error
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, value, c.loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func daysInMonth(month, year int) int {
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// cycleStart mengembalikan tanggal mulai siklus bulanan. Hari yang tidak ada di bulan
+// tersebut (misal 31 di bulan Februari) dimundurkan ke hari terakhir bulan itu.
+func cycleStart(month, year, day int, loc *time.Location) time.Time {
+	first := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc) // Juga menormalkan bulan 13 dst.
+	day = min(day, daysInMonth(int(first.Month()), first.Year()))
+	return first.AddDate(0, 0, day-1)
+}
+
+// monthRange mengembalikan awal dan akhir (inklusif) bulan keuangan (month, year)
+func (c calendar) monthRange(month, year int) (time.Time, time.Time) {
+	start := cycleStart(month, year, c.monthStartDay, c.loc)
+	return start, cycleStart(month+1, year, c.monthStartDay, c.loc).Add(-time.Nanosecond)
+}
+
+// monthOf mengembalikan bulan keuangan yang memuat t
+func (c calendar) monthOf(t time.Time) (int, int) {
+	t = t.In(c.loc)
+	month, year := int(t.Month()), t.Year()
+	if start, _ := c.monthRange(month, year); t.Before(start) {
+		month--
+		if month == 0 {
+			month, year = 12, year-1
+		}
+	}
+	return month, year
+}
+
+// clampToMonth membatasi ref ke dalam bulan keuangan (month, year)
+func (c calendar) clampToMonth(ref time.Time, month, year int) time.Time {
+	start, end := c.monthRange(month, year)
+	switch {
+	case ref.Before(start):
+		return start
+	case ref.After(end):
+		return end
+	}
+	return ref
+}
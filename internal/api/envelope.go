@@ -0,0 +1,443 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// EnvelopeError adalah kesalahan dari permintaan envelope yang tidak bisa dipenuhi
+// (misal uang tidak cukup). Dikirim ke client sebagai 422.
+type EnvelopeError struct {
+	Message string
+}
+
+func (e *EnvelopeError) Error() string { return e.Message }
+
+// envelopeLedger berisi data mentah mode envelope sampai bulan target, per indeks bulan.
+// Kategori dikunci dengan categoryKey, jadi budget "Makanan" dan transaksi "makanan" masuk
+// ke envelope yang sama.
+type envelopeLedger struct {
+	assigned map[string]map[int]int64 // kategori -> bulan -> alokasi (budgets.amount)
+	spent    map[string]map[int]int64 // kategori -> bulan -> pengeluaran
+	names    map[string]string        // kategori -> nama yang ditampilkan (ejaan budget terakhir)
+	income   map[int]int64            // bulan -> pemasukan
+	opening  int64                    // Total saldo semua akun di awal bulan pertama
+	start    int                      // Bulan pertama yang punya alokasi
+}
+
+func addMonthly(m map[string]map[int]int64, category string, idx int, amount int64) {
+	key := categoryKey(category)
+	if m[key] == nil {
+		m[key] = make(map[int]int64)
+	}
+	m[key][idx] += amount
+}
+
+// loadEnvelopeLedger membaca alokasi, pengeluaran dan pemasukan dari awal mode envelope
+// (bulan pertama yang punya budget) sampai bulan target
+func (s *Store) loadEnvelopeLedger(ctx context.Context, month, year int) (*envelopeLedger, error) {
+	target := monthIndex(month, year)
+	ledger := &envelopeLedger{
+		assigned: make(map[string]map[int]int64),
+		spent:    make(map[string]map[int]int64),
+		names:    make(map[string]string),
+		income:   make(map[int]int64),
+		start:    target,
+	}
+
+	// 1. Alokasi. Hanya budget bulanan (monthly/custom) yang menjadi isi envelope; budget
+	// mingguan dan tahunan adalah batas pengeluaran dengan periode lain, bukan alokasi bulan itu.
+	rows, err := s.Pool.Query(ctx, `
+		SELECT category_name, month, year, amount FROM budgets
+		WHERE (year * 12 + month - 1) <= $1 AND period_type IN ('monthly', 'custom')
+		ORDER BY year, month`, target)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var category string
+		var m, y int
+		var amount int64
+		if err := rows.Scan(&category, &m, &y, &amount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		idx := monthIndex(m, y)
+		ledger.start = min(ledger.start, idx)
+		addMonthly(ledger.assigned, category, idx, amount)
+		ledger.names[categoryKey(category)] = category
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	cal := calendarFrom(ctx)
+	startDate, _ := cal.monthRange(ledger.start%12+1, ledger.start/12)
+	_, endDate := cal.monthRange(month, year)
+
+	// 2. Pemasukan & pengeluaran per hari, lalu dikelompokkan ke bulan keuangan
+	rows, err = s.Pool.Query(ctx, `
+		SELECT type, category, date_trunc('day', date AT TIME ZONE $3)::date, SUM(amount)
+		FROM transactions
+		WHERE type IN ('income', 'expense') AND date >= $1 AND date <= $2
+		GROUP BY 1, 2, 3`, startDate, endDate, cal.loc.String())
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var txType, category string
+		var day time.Time
+		var amount int64
+		if err := rows.Scan(&txType, &category, &day, &amount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		idx := monthIndex(cal.monthOf(cal.atDate(day)))
+		if txType == "income" {
+			ledger.income[idx] += amount
+		} else {
+			addMonthly(ledger.spent, category, idx, amount)
+			if _, ok := ledger.names[categoryKey(category)]; !ok {
+				ledger.names[categoryKey(category)] = category
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 3. Uang yang sudah ada di awal: saldo sekarang dikurangi efek transaksi sejak awal
+	err = s.Pool.QueryRow(ctx, `
+		SELECT
+			(SELECT COALESCE(SUM(current_balance), 0) FROM accounts) -
+			(SELECT COALESCE(SUM(CASE WHEN type = 'income' THEN amount WHEN type = 'expense' THEN -amount ELSE 0 END), 0)
+			 FROM transactions WHERE date >= $1)`, startDate).Scan(&ledger.opening)
+	if err != nil {
+		return nil, err
+	}
+
+	return ledger, nil
+}
+
+// compute menghitung saldo envelope bulan target. Saldo positif selalu dibawa ke bulan
+// berikutnya. Saldo negatif (overspending) yang tidak ditutup di bulannya di-nol-kan dan
+// mengurangi "ready to assign" bulan berikutnya.
+func (l *envelopeLedger) compute(month, year int) *models.EnvelopeMonth {
+	target := monthIndex(month, year)
+	result := &models.EnvelopeMonth{
+		Month:      month,
+		Year:       year,
+		StartMonth: l.start%12 + 1,
+		StartYear:  l.start / 12,
+		Income:     l.income[target],
+		Envelopes:  make([]models.Envelope, 0),
+	}
+
+	categories := make(map[string]bool)
+	for c := range l.assigned {
+		categories[c] = true
+	}
+	for c := range l.spent {
+		categories[c] = true
+	}
+
+	carry := make(map[string]int64)
+	var totalIncome, totalAssigned, uncoveredOverspending int64
+	for idx := l.start; idx <= target; idx++ {
+		totalIncome += l.income[idx]
+		for c := range categories {
+			assigned, spent := l.assigned[c][idx], l.spent[c][idx]
+			totalAssigned += assigned
+			balance := carry[c] + assigned - spent
+
+			if idx == target {
+				if carry[c] == 0 && assigned == 0 && spent == 0 {
+					continue
+				}
+				env := models.Envelope{CategoryName: l.names[c], CarriedOver: carry[c], Assigned: assigned, Spent: spent, Balance: balance}
+				if balance < 0 {
+					env.Overspent = -balance
+				}
+				result.Assigned += assigned
+				result.Envelopes = append(result.Envelopes, env)
+				continue
+			}
+
+			if balance < 0 {
+				uncoveredOverspending += -balance
+				balance = 0
+			}
+			carry[c] = balance
+		}
+	}
+
+	sort.Slice(result.Envelopes, func(i, j int) bool {
+		return result.Envelopes[i].CategoryName < result.Envelopes[j].CategoryName
+	})
+	result.ReadyToAssign = l.opening + totalIncome - totalAssigned - uncoveredOverspending
+	return result
+}
+
+// GetEnvelopeMonth mengembalikan semua envelope dan "ready to assign" untuk satu bulan
+func (s *Store) GetEnvelopeMonth(ctx context.Context, month, year int) (*models.EnvelopeMonth, error) {
+	ledger, err := s.loadEnvelopeLedger(ctx, month, year)
+	if err != nil {
+		return nil, err
+	}
+	return ledger.compute(month, year), nil
+}
+
+// findEnvelope mencari envelope tanpa membedakan huruf besar/kecil nama kategori
+func findEnvelope(m *models.EnvelopeMonth, category string) models.Envelope {
+	for _, env := range m.Envelopes {
+		if categoryKey(env.CategoryName) == categoryKey(category) {
+			return env
+		}
+	}
+	return models.Envelope{CategoryName: category}
+}
+
+// movable adalah uang yang bisa diambil dari sebuah envelope: tidak lebih dari saldonya, dan
+// tidak lebih dari alokasi bulan ini agar budgets.amount tidak menjadi negatif (rollover dan
+// persentase progres mengandalkan amount >= 0). Sisa dari bulan lalu tidak bisa dipindah.
+func movable(env models.Envelope) int64 {
+	return max(min(env.Balance, env.Assigned), 0)
+}
+
+// addToEnvelope menambah alokasi (budgets.amount) sebuah kategori di bulan tertentu.
+// Budget mingguan/tahunan di bulan itu tidak diubah.
+func addToEnvelope(ctx context.Context, tx pgx.Tx, category string, month, year int, delta int64) error {
+	ct, err := tx.Exec(ctx, `
+		INSERT INTO budgets (category_name, month, year, amount)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (category_name, month, year)
+		DO UPDATE SET amount = budgets.amount + EXCLUDED.amount
+		WHERE budgets.period_type IN ('monthly', 'custom')`,
+		category, month, year, delta)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return &EnvelopeError{Message: fmt.Sprintf("category %q has a weekly or yearly budget this month; envelopes only use monthly budgets", category)}
+	}
+	return checkBudgetOverlap(ctx, tx, category, month, year)
+}
+
+// lockEnvelopes mencegah dua permintaan envelope berjalan bersamaan agar pengecekan
+// saldo tidak balapan. Lock dilepas otomatis saat transaksi selesai.
+func lockEnvelopes(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('envelopes'))`)
+	return err
+}
+
+// AssignToEnvelope mengalokasikan uang dari "ready to assign" ke sebuah envelope.
+// Amount negatif mengembalikan uang dari envelope ke "ready to assign".
+func (s *Store) AssignToEnvelope(ctx context.Context, req models.EnvelopeAssignRequest) (*models.EnvelopeMonth, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	if err := lockEnvelopes(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	current, err := s.GetEnvelopeMonth(ctx, req.Month, req.Year)
+	if err != nil {
+		return nil, err
+	}
+	env := findEnvelope(current, req.CategoryName)
+	if req.Amount > current.ReadyToAssign {
+		return nil, &EnvelopeError{Message: fmt.Sprintf("only %d is ready to assign", current.ReadyToAssign)}
+	}
+	if req.Amount < 0 && -req.Amount > movable(env) {
+		return nil, &EnvelopeError{Message: fmt.Sprintf("envelope %q only has %d available to unassign", req.CategoryName, movable(env))}
+	}
+
+	if err := addToEnvelope(ctx, tx, env.CategoryName, req.Month, req.Year, req.Amount); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return s.GetEnvelopeMonth(ctx, req.Month, req.Year)
+}
+
+// MoveBetweenEnvelopes memindahkan uang dari satu envelope ke envelope lain di bulan yang sama.
+// Jika coverOverspending bernilai true dan Amount 0, yang dipindahkan adalah kekurangan
+// envelope tujuan (sebanyak yang tersedia di envelope asal).
+func (s *Store) MoveBetweenEnvelopes(ctx context.Context, req models.EnvelopeMoveRequest, coverOverspending bool) (*models.EnvelopeMonth, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	if err := lockEnvelopes(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	current, err := s.GetEnvelopeMonth(ctx, req.Month, req.Year)
+	if err != nil {
+		return nil, err
+	}
+	from := findEnvelope(current, req.FromCategory)
+	to := findEnvelope(current, req.ToCategory)
+
+	amount := req.Amount
+	if coverOverspending {
+		if to.Overspent == 0 {
+			return nil, &EnvelopeError{Message: fmt.Sprintf("envelope %q is not overspent", req.ToCategory)}
+		}
+		if amount == 0 {
+			amount = min(to.Overspent, movable(from))
+		}
+	}
+	if amount <= 0 {
+		return nil, &EnvelopeError{Message: fmt.Sprintf("envelope %q has no money to move", req.FromCategory)}
+	}
+	if amount > movable(from) {
+		return nil, &EnvelopeError{Message: fmt.Sprintf("envelope %q only has %d available to move", req.FromCategory, movable(from))}
+	}
+
+	if err := addToEnvelope(ctx, tx, from.CategoryName, req.Month, req.Year, -amount); err != nil {
+		return nil, err
+	}
+	if err := addToEnvelope(ctx, tx, to.CategoryName, req.Month, req.Year, amount); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return s.GetEnvelopeMonth(ctx, req.Month, req.Year)
+}
+
+func validEnvelopeMonth(month, year int) error {
+	if month < 1 || month > 12 || year < 1 {
+		return errors.New("Invalid month or year")
+	}
+	return nil
+}
+
+func respondEnvelopeError(w http.ResponseWriter, err error) {
+	var envErr *EnvelopeError
+	if errors.As(err, &envErr) {
+		respondError(w, http.StatusUnprocessableEntity, envErr.Message)
+		return
+	}
+	if errors.Is(err, ErrBudgetOverlap) {
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	respondError(w, http.StatusInternalServerError, err.Error())
+}
+
+// HandleGetEnvelopes menangani GET /api/envelopes?month=&year=
+func (s *Store) HandleGetEnvelopes(w http.ResponseWriter, r *http.Request) {
+	month, year := parseMonthYear(r)
+
+	result, err := s.GetEnvelopeMonth(r.Context(), month, year)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// HandleAssignEnvelope menangani POST /api/envelopes/assign
+func (s *Store) HandleAssignEnvelope(w http.ResponseWriter, r *http.Request) {
+	var req models.EnvelopeAssignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	req.CategoryName = strings.TrimSpace(req.CategoryName)
+	if req.CategoryName == "" || req.Amount == 0 {
+		respondError(w, http.StatusBadRequest, "category_name and a non-zero amount are required")
+		return
+	}
+	if err := validEnvelopeMonth(req.Month, req.Year); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := s.AssignToEnvelope(r.Context(), req)
+	if err != nil {
+		respondEnvelopeError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// decodeEnvelopeMove membaca dan memvalidasi body untuk /envelopes/move dan /envelopes/cover
+func decodeEnvelopeMove(w http.ResponseWriter, r *http.Request) (models.EnvelopeMoveRequest, bool) {
+	var req models.EnvelopeMoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request payload")
+		return req, false
+	}
+	req.FromCategory = strings.TrimSpace(req.FromCategory)
+	req.ToCategory = strings.TrimSpace(req.ToCategory)
+	if req.FromCategory == "" || req.ToCategory == "" || categoryKey(req.FromCategory) == categoryKey(req.ToCategory) {
+		respondError(w, http.StatusBadRequest, "from_category and to_category are required and must differ")
+		return req, false
+	}
+	if req.Amount < 0 {
+		respondError(w, http.StatusBadRequest, "Amount cannot be negative")
+		return req, false
+	}
+	if err := validEnvelopeMonth(req.Month, req.Year); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return req, false
+	}
+	return req, true
+}
+
+// HandleMoveEnvelope menangani POST /api/envelopes/move
+func (s *Store) HandleMoveEnvelope(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeEnvelopeMove(w, r)
+	if !ok {
+		return
+	}
+	if req.Amount == 0 {
+		respondError(w, http.StatusBadRequest, "Amount cannot be zero")
+		return
+	}
+
+	result, err := s.MoveBetweenEnvelopes(r.Context(), req, false)
+	if err != nil {
+		respondEnvelopeError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// HandleCoverOverspending menangani POST /api/envelopes/cover. Tanpa amount, seluruh
+// kekurangan to_category ditutup dari from_category (sebanyak saldo yang tersedia).
+func (s *Store) HandleCoverOverspending(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeEnvelopeMove(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := s.MoveBetweenEnvelopes(r.Context(), req, true)
+	if err != nil {
+		respondEnvelopeError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
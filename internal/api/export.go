@@ -0,0 +1,263 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/exporter"
+	"github.com/bramszs/finance-tracker/internal/importer"
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// csvFlushEvery adalah jumlah baris sebelum CSV di-flush ke client
+const csvFlushEvery = 500
+
+// sentWriter mencatat apakah sudah ada byte yang dikirim ke client. Setelah itu status
+// dan header response tidak bisa diganti lagi.
+type sentWriter struct {
+	w    io.Writer
+	sent bool
+}
+
+func (sw *sentWriter) Write(p []byte) (int, error) {
+	sw.sent = true
+	return sw.w.Write(p)
+}
+
+// csvColumn adalah satu kolom yang bisa dipilih lewat query param columns
+type csvColumn struct {
+	key      string
+	headerID string
+	headerEN string
+	value    func(tx *models.TransactionExport) string
+}
+
+// csvColumns dalam urutan default. Header Indonesia sama dengan ekspor lama
+// sehingga file bisa diimpor kembali lewat /import/csv.
+var csvColumns = []csvColumn{
+	{"id", "ID Transaksi", "Transaction ID", func(tx *models.TransactionExport) string {
+		return strconv.FormatInt(tx.ID, 10)
+	}},
+	{"date", "Tanggal", "Date", func(tx *models.TransactionExport) string {
+		return tx.Date.Format("2006-01-02")
+	}},
+	{"type", "Tipe", "Type", func(tx *models.TransactionExport) string {
+		return tx.Type
+	}},
+	{"amount", "Jumlah (Rp)", "Amount (IDR)", func(tx *models.TransactionExport) string {
+		return strconv.FormatFloat(float64(tx.Amount)/100, 'f', 2, 64)
+	}},
+	{"category", "Kategori", "Category", func(tx *models.TransactionExport) string {
+		return tx.Category
+	}},
+	{"description", "Deskripsi", "Description", func(tx *models.TransactionExport) string {
+		return tx.Description
+	}},
+	{"account", "Akun Asal", "Account", func(tx *models.TransactionExport) string {
+		return tx.AccountName.String // .String akan kosong jika NULL
+	}},
+	{"destination_account", "Akun Tujuan", "Destination Account", func(tx *models.TransactionExport) string {
+		return tx.DestinationAccountName.String
+	}},
+}
+
+// parseCSVColumns membaca daftar kolom "date,amount,category". Kosong berarti semua kolom.
+func parseCSVColumns(raw string) ([]csvColumn, error) {
+	if strings.TrimSpace(raw) == "" {
+		return csvColumns, nil
+	}
+
+	var selected []csvColumn
+	for _, key := range strings.Split(raw, ",") {
+		key = strings.ToLower(strings.TrimSpace(key))
+		found := false
+		for _, col := range csvColumns {
+			if col.key == key {
+				selected = append(selected, col)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown column %q", key)
+		}
+	}
+	return selected, nil
+}
+
+// HandleExportCSV mengekspor transaksi sebagai CSV. Filter sama dengan GET /transactions
+// (start, end, account_id, type, category), ditambah columns, delimiter dan locale (id/en).
+// Baris langsung ditulis dari hasil query sehingga ekspor besar tidak ditampung di memori.
+func (s *Store) HandleExportCSV(w http.ResponseWriter, r *http.Request) {
+	// 1. Baca filter dan opsi format
+	filter, err := parseTransactionFilter(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	columns, err := parseCSVColumns(r.URL.Query().Get("columns"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	delimiter, err := importer.ParseDelimiter(r.URL.Query().Get("delimiter"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid delimiter, use , ; tab or |")
+		return
+	}
+	locale := strings.ToLower(r.URL.Query().Get("locale"))
+	if locale == "" {
+		locale = "id"
+	}
+	if locale != "id" && locale != "en" {
+		respondError(w, http.StatusBadRequest, "Invalid locale, use id or en")
+		return
+	}
+
+	// 2. Set Header agar browser men-download
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"laporan-transaksi.csv\"")
+
+	// 3. Buat CSV Writer
+	out := &sentWriter{w: w}
+	writer := csv.NewWriter(out)
+	writer.Comma = delimiter
+	flusher, _ := w.(http.Flusher)
+	flush := func() error {
+		writer.Flush()
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return writer.Error()
+	}
+
+	// 4. Tulis Baris Header
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.headerID
+		if locale == "en" {
+			header[i] = col.headerEN
+		}
+	}
+	if err := writer.Write(header); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to write CSV header")
+		return
+	}
+
+	// 5. Tulis Baris Data langsung dari database
+	count := 0
+	row := make([]string, len(columns))
+	err = s.StreamTransactionsForExport(r.Context(), filter, func(tx *models.TransactionExport) error {
+		for i, col := range columns {
+			row[i] = col.value(tx)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+		count++
+		if count%csvFlushEvery == 0 {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil && !out.sent {
+		// Belum ada yang dikirim ke client, jadi masih bisa mengirim error
+		w.Header().Del("Content-Disposition")
+		respondError(w, http.StatusInternalServerError, "Failed to fetch transactions")
+		return
+	}
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		// Header sudah terkirim, hanya bisa dicatat
+		log.Printf("Error writing CSV export after %d rows: %v", count, err)
+	}
+}
+
+// loadJournal mengambil semua akun dan transaksi untuk ekspor plain-text accounting
+func (s *Store) loadJournal(ctx context.Context) (exporter.Journal, error) {
+	accounts, err := s.GetAccounts(ctx)
+	if err != nil {
+		return exporter.Journal{}, err
+	}
+	transactions, err := s.GetTransactionsForExport(ctx, models.TransactionFilter{})
+	if err != nil {
+		return exporter.Journal{}, err
+	}
+	return exporter.Journal{Accounts: accounts, Transactions: transactions, Now: time.Now().In(calendarFrom(ctx).loc)}, nil
+}
+
+// serveJournal menulis jurnal sebagai file download dengan writer yang diberikan
+func (s *Store) serveJournal(w http.ResponseWriter, r *http.Request, filename string, write func(io.Writer, exporter.Journal) error) {
+	journal, err := s.loadJournal(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch transactions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	if err := write(w, journal); err != nil {
+		// Header sudah terkirim, hanya bisa dicatat
+		log.Printf("Failed to write %s: %v", filename, err)
+	}
+}
+
+// HandleExportLedger mengekspor semua transaksi dalam format ledger-cli/hledger
+func (s *Store) HandleExportLedger(w http.ResponseWriter, r *http.Request) {
+	s.serveJournal(w, r, "laporan-transaksi.ledger", exporter.WriteLedger)
+}
+
+// HandleExportBeancount mengekspor semua transaksi dalam format Beancount
+func (s *Store) HandleExportBeancount(w http.ResponseWriter, r *http.Request) {
+	s.serveJournal(w, r, "laporan-transaksi.beancount", exporter.WriteBeancount)
+}
+
+// HandleExportXLSX mengekspor laporan Excel dengan filter yang sama seperti /summary
+// (start, end, account_id)
+func (s *Store) HandleExportXLSX(w http.ResponseWriter, r *http.Request) {
+	startDate, endDate, err := parseDateRange(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	accountID := parseAccountID(r)
+	ctx := r.Context()
+
+	wb := exporter.Workbook{StartDate: startDate, EndDate: endDate}
+	wb.Transactions, err = s.GetTransactionsForExport(ctx, models.TransactionFilter{StartDate: startDate, EndDate: endDate, AccountID: accountID})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch transactions")
+		return
+	}
+	wb.Categories, err = s.GetCategorySummary(ctx, startDate, endDate, accountID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch category summary")
+		return
+	}
+	wb.Accounts, err = s.GetAccountBalancesForPeriod(ctx, startDate, endDate, accountID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch account balances")
+		return
+	}
+	wb.Budgets, err = s.GetBudgetVsActual(ctx, startDate, endDate, accountID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch budgets")
+		return
+	}
+
+	filename := fmt.Sprintf("laporan-keuangan-%s-%s.xlsx", startDate.Format("20060102"), endDate.Format("20060102"))
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	if err := exporter.WriteXLSX(w, wb); err != nil {
+		log.Printf("Failed to write %s: %v", filename, err)
+	}
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// maxForecastDays membatasi rentang upcoming & forecast
+const maxForecastDays = 366
+
+// parseDays membaca query param ?days=, default 30 dan paling banyak maxForecastDays
+func parseDays(r *http.Request) int {
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days < 1 {
+		return 30
+	}
+	return min(days, maxForecastDays)
+}
+
+// GetUpcomingOccurrences menjabarkan setiap jadwal aktif menjadi kejadian konkret sampai
+// tanggal end (inklusif), dengan aturan yang sama seperti worker: end_date, max_occurrences,
+// rrule dan pergeseran hari kerja. Kejadian yang sudah jatuh tempo tapi belum diposting ikut
+// dikembalikan dengan Overdue = true.
+func (s *Store) GetUpcomingOccurrences(ctx context.Context, end time.Time) ([]models.UpcomingOccurrence, error) {
+	cal := calendarFrom(ctx)
+	today, endKey := dateKey(cal.today()), dateKey(end)
+
+	rows, err := s.Pool.Query(ctx, `SELECT `+recurringColumns+` FROM recurring_transactions
+		WHERE next_due_date <= $1 AND `+recurringActiveClause, endKey)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := scanRecurring(rows)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := s.loadHolidays(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := make([]models.UpcomingOccurrence, 0)
+	for _, rt := range schedules {
+		remaining := -1 // -1 = tanpa batas
+		if rt.MaxOccurrences != nil {
+			remaining = *rt.MaxOccurrences - rt.Occurrences
+		}
+
+		due := rt.NextDueDate
+		for n := 0; dateKey(due) <= endKey && n != remaining; n++ {
+			if rt.EndDate != nil && dateKey(due) > dateKey(*rt.EndDate) {
+				break
+			}
+			occurrences = append(occurrences, models.UpcomingOccurrence{
+				RecurringID:          rt.ID,
+				Date:                 cal.atDate(due),
+				Amount:               rt.Amount,
+				Type:                 rt.Type,
+				Category:             rt.Category,
+				Description:          rt.Description,
+				AccountID:            rt.AccountID,
+				DestinationAccountID: rt.DestinationAccountID,
+				Overdue:              dateKey(due) < today,
+			})
+
+			// Jadwal yang aturannya rusak sudah dicatat worker di last_error; cukup berhenti di sini
+			if due, err = nextOccurrence(rt, due, holidays); err != nil {
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		if !occurrences[i].Date.Equal(occurrences[j].Date) {
+			return occurrences[i].Date.Before(occurrences[j].Date)
+		}
+		return occurrences[i].RecurringID < occurrences[j].RecurringID
+	})
+	return occurrences, nil
+}
+
+// GetForecast memproyeksikan saldo setiap akun per hari, mulai hari ini selama days hari,
+// dari current_balance ditambah kejadian jadwal berulang. Kejadian yang terlambat dihitung
+// di hari ini. accountID 0 berarti semua akun.
+func (s *Store) GetForecast(ctx context.Context, days int, accountID int64) (*models.ForecastResponse, error) {
+	cal := calendarFrom(ctx)
+	start := cal.today()
+	end := start.AddDate(0, 0, days-1)
+
+	occurrences, err := s.GetUpcomingOccurrences(ctx, end)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := s.GetAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Siapkan hari-hari proyeksi untuk setiap akun
+	dayIndex := make(map[string]int, days)
+	for i := 0; i < days; i++ {
+		dayIndex[dateKey(start.AddDate(0, 0, i))] = i
+	}
+	forecasts := make(map[int64]*models.AccountForecast)
+	response := &models.ForecastResponse{StartDate: start, EndDate: end, Accounts: make([]models.AccountForecast, 0)}
+	for _, acc := range accounts {
+		if accountID > 0 && acc.ID != accountID {
+			continue
+		}
+		f := &models.AccountForecast{AccountID: acc.ID, Name: acc.Name, StartingBalance: acc.CurrentBalance,
+			Days: make([]models.ForecastDay, days)}
+		for i := range f.Days {
+			f.Days[i].Date = start.AddDate(0, 0, i)
+		}
+		forecasts[acc.ID] = f
+	}
+
+	// 2. Masukkan setiap kejadian ke hari dan akunnya
+	for _, occ := range occurrences {
+		i := 0 // Terlambat: dihitung hari ini
+		if !occ.Overdue {
+			i = dayIndex[dateKey(occ.Date)]
+		}
+		add := func(id, inflow, outflow int64) {
+			if f, ok := forecasts[id]; ok {
+				f.Days[i].Inflow += inflow
+				f.Days[i].Outflow += outflow
+			}
+		}
+		switch occ.Type {
+		case "income":
+			add(occ.AccountID, occ.Amount, 0)
+		case "expense":
+			add(occ.AccountID, 0, occ.Amount)
+		case "transfer":
+			add(occ.AccountID, 0, occ.Amount)
+			if occ.DestinationAccountID != nil {
+				add(*occ.DestinationAccountID, occ.Amount, 0)
+			}
+		}
+	}
+
+	// 3. Hitung saldo berjalan & tandai hari yang negatif (urutan akun mengikuti GetAccounts)
+	for _, acc := range accounts {
+		f, ok := forecasts[acc.ID]
+		if !ok {
+			continue
+		}
+		balance := f.StartingBalance
+		f.LowestBalance, f.LowestDate = balance, start
+		for i := range f.Days {
+			day := &f.Days[i]
+			balance += day.Inflow - day.Outflow
+			day.Balance = balance
+			if balance < f.LowestBalance {
+				f.LowestBalance, f.LowestDate = balance, day.Date
+			}
+			if balance < 0 {
+				day.Negative = true
+				f.NegativeDays++
+				if f.FirstNegative == nil {
+					f.FirstNegative = &day.Date
+				}
+			}
+		}
+		f.EndingBalance = balance
+		response.Accounts = append(response.Accounts, *f)
+	}
+	return response, nil
+}
+
+// HandleGetUpcomingRecurring menangani GET /api/recurring/upcoming?days=60
+func (s *Store) HandleGetUpcomingRecurring(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	start := calendarFrom(ctx).today()
+	end := start.AddDate(0, 0, parseDays(r)-1)
+
+	occurrences, err := s.GetUpcomingOccurrences(ctx, end)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := models.UpcomingResponse{StartDate: start, EndDate: end, Occurrences: occurrences}
+	for _, occ := range occurrences {
+		switch occ.Type {
+		case "income":
+			response.Income += occ.Amount
+		case "expense":
+			response.Expense += occ.Amount
+		}
+	}
+	respondJSON(w, http.StatusOK, response)
+}
+
+// HandleGetForecast menangani GET /api/forecast?days=60&account_id=1
+func (s *Store) HandleGetForecast(w http.ResponseWriter, r *http.Request) {
+	forecast, err := s.GetForecast(r.Context(), parseDays(r), parseAccountID(r))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, forecast)
+}
@@ -1,7 +1,6 @@
 package api
 
 import (
-	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -44,6 +43,115 @@ func parseAccountID(r *http.Request) int64 {
 	}
 	return accID
 }
+
+// parseDateRange membaca query param start & end (yyyy-mm-dd).
+// Jika salah satu kosong, periode default adalah bulan keuangan pengguna saat ini.
+func parseDateRange(r *http.Request) (time.Time, time.Time, error) {
+	startDateStr := r.URL.Query().Get("start")
+	endDateStr := r.URL.Query().Get("end")
+
+	if startDateStr == "" || endDateStr == "" {
+		cal := calendarFrom(r.Context())
+		startDate, endDate := cal.monthRange(cal.monthOf(time.Now()))
+		return startDate, endDate, nil
+	}
+
+	loc := calendarFrom(r.Context()).loc
+	layout := "2006-01-02"
+	startDate, err := time.ParseInLocation(layout, startDateStr, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("Invalid start date format, use yyyy-mm-dd")
+	}
+	endDate, err := time.ParseInLocation(layout, endDateStr, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("Invalid end date format, use yyyy-mm-dd")
+	}
+	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 0, endDate.Location())
+	return startDate, endDate, nil
+}
+
+// parseTransactionFilter membaca filter daftar transaksi: start, end (yyyy-mm-dd,
+// masing-masing opsional), account_id, type dan category.
+func parseTransactionFilter(r *http.Request) (models.TransactionFilter, error) {
+	q := r.URL.Query()
+	filter := models.TransactionFilter{
+		AccountID: parseAccountID(r),
+		Type:      strings.ToLower(strings.TrimSpace(q.Get("type"))),
+		Category:  strings.TrimSpace(q.Get("category")),
+	}
+
+	loc := calendarFrom(r.Context()).loc
+	layout := "2006-01-02"
+	if startDateStr := q.Get("start"); startDateStr != "" {
+		startDate, err := time.ParseInLocation(layout, startDateStr, loc)
+		if err != nil {
+			return filter, errors.New("Invalid start date format, use yyyy-mm-dd")
+		}
+		filter.StartDate = startDate
+	}
+	if endDateStr := q.Get("end"); endDateStr != "" {
+		endDate, err := time.ParseInLocation(layout, endDateStr, loc)
+		if err != nil {
+			return filter, errors.New("Invalid end date format, use yyyy-mm-dd")
+		}
+		filter.EndDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 0, endDate.Location())
+	}
+
+	switch filter.Type {
+	case "", "income", "expense", "transfer":
+	default:
+		return filter, errors.New("Invalid type, use income, expense or transfer")
+	}
+	return filter, nil
+}
+
+// parseMonthYear membaca query param month & year.
+// Default ke bulan keuangan pengguna saat ini jika kosong atau tidak valid.
+func parseMonthYear(r *http.Request) (int, int) {
+	currentMonth, currentYear := calendarFrom(r.Context()).monthOf(time.Now())
+
+	month, err := strconv.Atoi(r.URL.Query().Get("month"))
+	if err != nil || month < 1 || month > 12 {
+		month = currentMonth // Default: bulan ini
+	}
+
+	year, err := strconv.Atoi(r.URL.Query().Get("year"))
+	if err != nil {
+		year = currentYear // Default: tahun ini
+	}
+	return month, year
+}
+
+// transactionPayload sama dengan models.Transaction, tetapi tanggalnya dibaca lewat
+// calendar.parseTime sehingga "2026-10-19" atau "2026-10-19T23:30" tanpa offset
+// dianggap waktu di zona waktu aplikasi
+type transactionPayload struct {
+	models.Transaction
+	Date string `json:"date"`
+}
+
+func (p transactionPayload) toTransaction(cal calendar) (models.Transaction, error) {
+	tx := p.Transaction
+	if p.Date == "" {
+		return tx, nil
+	}
+	date, err := cal.parseTime(p.Date)
+	if err != nil {
+		return tx, errors.New("Invalid date format, use yyyy-mm-dd or RFC 3339")
+	}
+	tx.Date = date
+	return tx, nil
+}
+
+// decodeTransaction membaca satu transaksi dari body request
+func decodeTransaction(r *http.Request) (models.Transaction, error) {
+	var payload transactionPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return models.Transaction{}, errors.New("Invalid request payload")
+	}
+	return payload.toTransaction(calendarFrom(r.Context()))
+}
+
 func respondJSON(w http.ResponseWriter, status int, payload interface{}) {
 	response, err := json.Marshal(payload)
 	if err != nil {
@@ -156,60 +264,6 @@ func (s *Store) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	// 4. Kirim token ke client
 	respondJSON(w, http.StatusOK, map[string]string{"token": tokenString})
 }
-func (s *Store) HandleExportCSV(w http.ResponseWriter, r *http.Request) {
-	// 1. Ambil semua data
-	transactions, err := s.GetTransactionsForExport(r.Context())
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to fetch transactions")
-		return
-	}
-
-	// 2. Set Header agar browser men-download
-	w.Header().Set("Content-Type", "text/csv")
-	w.Header().Set("Content-Disposition", "attachment; filename=\"laporan-transaksi.csv\"")
-
-	// 3. Buat CSV Writer
-	writer := csv.NewWriter(w)
-	defer writer.Flush()
-
-	// 4. Tulis Baris Header
-	header := []string{
-		"ID Transaksi", "Tanggal", "Tipe", "Jumlah (Rp)", "Kategori",
-		"Deskripsi", "Akun Asal", "Akun Tujuan",
-	}
-	if err := writer.Write(header); err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to write CSV header")
-		return
-	}
-
-	// 5. Tulis Baris Data
-	for _, tx := range transactions {
-		// Konversi data ke string
-		amount := strconv.FormatFloat(float64(tx.Amount)/100, 'f', 2, 64)
-		date := tx.Date.Format("2006-01-02") // Format YYYY-MM-DD
-
-		accountName := tx.AccountName.String // .String akan kosong jika NULL
-		destAccountName := tx.DestinationAccountName.String
-
-		row := []string{
-			fmt.Sprintf("%d", tx.ID),
-			date,
-			tx.Type,
-			amount,
-			tx.Category,
-			tx.Description,
-			accountName,
-			destAccountName,
-		}
-
-		if err := writer.Write(row); err != nil {
-			// Sulit mengirim error di sini karena header sudah dikirim
-			// Cukup log saja
-			fmt.Printf("Error writing CSV row: %v\n", err)
-			return
-		}
-	}
-}
 func (s *Store) CreateCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	var req CreateCategoryRequest
 
@@ -251,31 +305,10 @@ func (s *Store) GetCategoriesHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Store) GetSummaryHandler(w http.ResponseWriter, r *http.Request) {
-	startDateStr := r.URL.Query().Get("start")
-	endDateStr := r.URL.Query().Get("end")
-
-	var startDate, endDate time.Time
-	var err error
-
-	if startDateStr == "" || endDateStr == "" {
-		now := time.Now()
-		startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-		endDate = startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
-	} else {
-		layout := "2006-01-02"
-		startDate, err = time.Parse(layout, startDateStr)
-		if err != nil {
-			respondError(w, http.StatusBadRequest, "Invalid start date format, use yyyy-mm-dd")
-			return
-		}
-
-		endDate, err = time.Parse(layout, endDateStr)
-		if err != nil {
-			respondError(w, http.StatusBadRequest, "Invalid end date format, use yyyy-mm-dd")
-			return
-		}
-
-		endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 0, endDate.Location())
+	startDate, endDate, err := parseDateRange(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	accountID := parseAccountID(r) // <-- Panggil helper baru
@@ -290,10 +323,9 @@ func (s *Store) GetSummaryHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Store) CreateTransactionHandler(w http.ResponseWriter, r *http.Request) {
-	var tx models.Transaction
-
-	if err := json.NewDecoder(r.Body).Decode(&tx); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request payload")
+	tx, err := decodeTransaction(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -301,10 +333,11 @@ func (s *Store) CreateTransactionHandler(w http.ResponseWriter, r *http.Request)
 		respondError(w, http.StatusBadRequest, "Amount cannot be zero")
 		return
 	}
-	// if tx.Type != "income" && tx.Type != "expense" {
-	// 	respondError(w, http.StatusBadRequest, "Type must be 'income' or 'expense'")
-	// 	return
-	// }
+	// Transfer hanya lewat POST /api/transfers agar validasinya (akun asal != tujuan, dst) tidak terlewati
+	if strings.EqualFold(strings.TrimSpace(tx.Type), "transfer") {
+		respondError(w, http.StatusBadRequest, "Use POST /api/transfers to create a transfer")
+		return
+	}
 	if tx.AccountID <= 0 {
 		// Jika frontend mengirim null, 0, atau string kosong,
 		// txData.AccountID akan jadi 0, dan kita TOLAK di sini.
@@ -335,8 +368,14 @@ func (s *Store) HandleGetTransactions(w http.ResponseWriter, r *http.Request) {
 		limit = 25 // Default 25 item per halaman
 	}
 
+	filter, err := parseTransactionFilter(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Panggil store dengan parameter baru
-	response, err := s.GetTransactions(r.Context(), page, limit)
+	response, err := s.GetTransactions(r.Context(), page, limit, filter)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -346,31 +385,11 @@ func (s *Store) HandleGetTransactions(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Store) GetCategorySummaryHandler(w http.ResponseWriter, r *http.Request) {
-	// Ambil query params
-	startDateStr := r.URL.Query().Get("start")
-	endDateStr := r.URL.Query().Get("end")
-
-	var startDate, endDate time.Time
-	var err error
-
-	// Jika parameter tidak ada, gunakan default bulan ini
-	if startDateStr == "" || endDateStr == "" {
-		now := time.Now()
-		startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-		endDate = startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
-	} else {
-		layout := "2006-01-02"
-		startDate, err = time.Parse(layout, startDateStr)
-		if err != nil {
-			respondError(w, http.StatusBadRequest, "Invalid start date format, use yyyy-mm-dd")
-			return
-		}
-		endDate, err = time.Parse(layout, endDateStr)
-		if err != nil {
-			respondError(w, http.StatusBadRequest, "Invalid end date format, use yyyy-mm-dd")
-			return
-		}
-		endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 0, endDate.Location())
+	// Ambil query params, default bulan ini
+	startDate, endDate, err := parseDateRange(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	// Panggil store
@@ -444,10 +463,10 @@ func (s *Store) UpdateTransactionHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var tx models.Transaction
 	// Decode JSON body ke struct
-	if err := json.NewDecoder(r.Body).Decode(&tx); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request payload")
+	tx, err := decodeTransaction(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -480,8 +499,21 @@ func (s *Store) HandleSetBudget(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "Missing required fields")
 		return
 	}
+	if err := validRollover(&budget); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validPeriod(&budget); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	if err := s.SetBudget(r.Context(), &budget); err != nil {
+	err := s.SetBudget(r.Context(), &budget)
+	if errors.Is(err, ErrBudgetOverlap) {
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -492,20 +524,7 @@ func (s *Store) HandleSetBudget(w http.ResponseWriter, r *http.Request) {
 // HandleGetBudgets menangani GET /api/budgets
 func (s *Store) HandleGetBudgets(w http.ResponseWriter, r *http.Request) {
 	// Ambil bulan dan tahun dari query param
-	// Default ke bulan & tahun saat ini
-	now := time.Now()
-	monthStr := r.URL.Query().Get("month")
-	yearStr := r.URL.Query().Get("year")
-
-	month, err := strconv.Atoi(monthStr)
-	if err != nil || month < 1 || month > 12 {
-		month = int(now.Month()) // Default: bulan ini
-	}
-
-	year, err := strconv.Atoi(yearStr)
-	if err != nil {
-		year = now.Year() // Default: tahun ini
-	}
+	month, year := parseMonthYear(r)
 
 	budgets, err := s.GetBudgets(r.Context(), month, year)
 	if err != nil {
@@ -528,17 +547,39 @@ func (s *Store) HandleGetRecurringTransactions(w http.ResponseWriter, r *http.Re
 // HandleCreateRecurringTransaction menangani POST /api/recurring
 func (s *Store) HandleCreateRecurringTransaction(w http.ResponseWriter, r *http.Request) {
 	var rt models.RecurringTransaction
-	if err := json.NewDecoder(r.Body).Decode(&rt); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request payload")
+	if err := decodeRecurring(r, &rt); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// (Tambahkan validasi di sini: cek amount, frequency, dll)
+	if rt.Interval == 0 {
+		rt.Interval = 1
+	}
+	if rt.StartDate.IsZero() {
+		respondError(w, http.StatusBadRequest, "start_date is required")
+		return
+	}
 
-	if err := s.CreateRecurringTransaction(r.Context(), &rt); err != nil {
+	if err := validateRecurring(&rt); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Jatuh tempo pertama: kejadian pertama aturan mulai start_date, digeser ke hari kerja jika perlu
+	holidays, err := s.loadHolidays(r.Context())
+	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if rt.NextDueDate, err = firstOccurrence(rt, holidays); err != nil {
+		respondRecurringError(w, err)
+		return
+	}
+
+	if err := s.CreateRecurringTransaction(r.Context(), &rt); err != nil {
+		respondRecurringError(w, err)
+		return
+	}
 	respondJSON(w, http.StatusCreated, rt)
 }
 
@@ -582,9 +623,9 @@ func (s *Store) HandleGetAccounts(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Store) HandleCreateTransfer(w http.ResponseWriter, r *http.Request) {
-	var txData models.Transaction
-	if err := json.NewDecoder(r.Body).Decode(&txData); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request payload")
+	txData, err := decodeTransaction(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -616,7 +657,7 @@ func (s *Store) HandleCreateTransfer(w http.ResponseWriter, r *http.Request) {
 	// Panggil logika store
 	if err := s.CreateTransfer(r.Context(), &txData); err != nil {
 		// Cek error spesifik
-		if err.Error() == "account not found, balance not updated" {
+		if errors.Is(err, ErrAccountNotFound) {
 			respondError(w, http.StatusBadRequest, "One of the accounts does not exist.")
 		} else {
 			respondError(w, http.StatusInternalServerError, err.Error())
@@ -626,3 +667,62 @@ func (s *Store) HandleCreateTransfer(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusCreated, txData)
 }
+
+// HandleCreateTransactionsBatch menangani POST /api/transactions/batch
+func (s *Store) HandleCreateTransactionsBatch(w http.ResponseWriter, r *http.Request) {
+	// Sama dengan models.BatchTransactionRequest, dengan tanggal yang boleh tanpa zona waktu
+	var req struct {
+		Transactions []transactionPayload `json:"transactions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if len(req.Transactions) == 0 {
+		respondError(w, http.StatusBadRequest, "No transactions in batch")
+		return
+	}
+	if len(req.Transactions) > MaxBatchTransactions {
+		respondError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("Batch cannot contain more than %d transactions", MaxBatchTransactions))
+		return
+	}
+
+	cal := calendarFrom(r.Context())
+	txs := make([]models.Transaction, len(req.Transactions))
+	var invalid []models.BatchRowError
+	for i, payload := range req.Transactions {
+		tx, err := payload.toTransaction(cal)
+		if err != nil {
+			invalid = append(invalid, models.BatchRowError{Index: i, Error: err.Error()})
+		}
+		txs[i] = tx
+	}
+	if len(invalid) > 0 {
+		respondBatchError(w, &BatchValidationError{Rows: invalid})
+		return
+	}
+
+	created, err := s.CreateTransactionsBatch(r.Context(), txs)
+	if err != nil {
+		respondBatchError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.BatchTransactionResponse{Created: created})
+}
+
+// respondBatchError mengirim SEMUA baris yang tidak valid sekaligus jika err adalah
+// BatchValidationError, selain itu dianggap error server
+func respondBatchError(w http.ResponseWriter, err error) {
+	var batchErr *BatchValidationError
+	if errors.As(err, &batchErr) {
+		respondJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":  batchErr.Error(),
+			"errors": batchErr.Rows,
+		})
+		return
+	}
+	respondError(w, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+	"github.com/gorilla/mux"
+)
+
+var ErrHolidayNotFound = errors.New("holiday not found")
+
+// holidaySet berisi tanggal libur (format dateKey). Sabtu & Minggu selalu dianggap libur.
+type holidaySet map[string]bool
+
+// isBusinessDay melaporkan apakah d bukan akhir pekan dan bukan hari libur
+func (h holidaySet) isBusinessDay(d time.Time) bool {
+	return d.Weekday() != time.Saturday && d.Weekday() != time.Sunday && !h[dateKey(d)]
+}
+
+// shift menggeser d ke hari kerja sebelum atau sesudahnya sesuai mode (models.Shift*)
+func (h holidaySet) shift(d time.Time, mode string) time.Time {
+	step := 0
+	switch mode {
+	case models.ShiftPrevious:
+		step = -1
+	case models.ShiftNext:
+		step = 1
+	default:
+		return d
+	}
+	// Batas 31 hari hanya pengaman jika hampir semua hari ditandai libur
+	for i := 0; i < 31 && !h.isBusinessDay(d); i++ {
+		d = d.AddDate(0, 0, step)
+	}
+	return d
+}
+
+// loadHolidays mengambil semua hari libur untuk pergeseran jadwal berulang
+func (s *Store) loadHolidays(ctx context.Context) (holidaySet, error) {
+	rows, err := s.Pool.Query(ctx, `SELECT date FROM holidays`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holidays := make(holidaySet)
+	for rows.Next() {
+		var date time.Time
+		if err := rows.Scan(&date); err != nil {
+			return nil, err
+		}
+		holidays[dateKey(date)] = true
+	}
+	return holidays, rows.Err()
+}
+
+// GetHolidays mengambil hari libur, hanya tahun year jika year > 0
+func (s *Store) GetHolidays(ctx context.Context, year int) ([]models.Holiday, error) {
+	query := `SELECT to_char(date, 'YYYY-MM-DD'), name, created_at FROM holidays`
+	args := []interface{}{}
+	if year > 0 {
+		query += ` WHERE EXTRACT(YEAR FROM date) = $1`
+		args = append(args, year)
+	}
+	query += ` ORDER BY date`
+
+	rows, err := s.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holidays := make([]models.Holiday, 0)
+	for rows.Next() {
+		var h models.Holiday
+		if err := rows.Scan(&h.Date, &h.Name, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		holidays = append(holidays, h)
+	}
+	return holidays, rows.Err()
+}
+
+// SetHoliday menambah hari libur, atau mengganti namanya jika tanggalnya sudah ada
+func (s *Store) SetHoliday(ctx context.Context, h *models.Holiday) error {
+	return s.Pool.QueryRow(ctx, `
+		INSERT INTO holidays (date, name) VALUES ($1, $2)
+		ON CONFLICT (date) DO UPDATE SET name = EXCLUDED.name
+		RETURNING created_at`,
+		h.Date, h.Name,
+	).Scan(&h.CreatedAt)
+}
+
+func (s *Store) DeleteHoliday(ctx context.Context, date string) error {
+	ct, err := s.Pool.Exec(ctx, `DELETE FROM holidays WHERE date = $1`, date)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrHolidayNotFound
+	}
+	return nil
+}
+
+// HandleGetHolidays menangani GET /api/holidays?year=2026
+func (s *Store) HandleGetHolidays(w http.ResponseWriter, r *http.Request) {
+	year, _ := strconv.Atoi(r.URL.Query().Get("year"))
+	holidays, err := s.GetHolidays(r.Context(), year)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, holidays)
+}
+
+// HandleSetHoliday menangani POST /api/holidays dengan body {"date": "2026-12-25", "name": "Natal"}
+func (s *Store) HandleSetHoliday(w http.ResponseWriter, r *http.Request) {
+	var h models.Holiday
+	if err := json.NewDecoder(r.Body).Decode(&h); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if _, err := time.Parse("2006-01-02", h.Date); err != nil {
+		respondError(w, http.StatusBadRequest, "date must be YYYY-MM-DD")
+		return
+	}
+	h.Name = strings.TrimSpace(h.Name)
+	if h.Name == "" || len(h.Name) > 100 {
+		respondError(w, http.StatusBadRequest, "name must be 1-100 characters")
+		return
+	}
+
+	if err := s.SetHoliday(r.Context(), &h); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, h)
+}
+
+// HandleDeleteHoliday menangani DELETE /api/holidays/{date}
+func (s *Store) HandleDeleteHoliday(w http.ResponseWriter, r *http.Request) {
+	date := mux.Vars(r)["date"]
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		respondError(w, http.StatusBadRequest, "date must be YYYY-MM-DD")
+		return
+	}
+
+	err := s.DeleteHoliday(r.Context(), date)
+	if errors.Is(err, ErrHolidayNotFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
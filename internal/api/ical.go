@@ -0,0 +1,362 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/exporter"
+	"github.com/bramszs/finance-tracker/internal/models"
+	"github.com/bramszs/finance-tracker/internal/recurrence"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// calendarFeedDays adalah rentang kejadian yang dijabarkan satu per satu untuk jadwal yang
+// digeser ke hari kerja (pergeseran itu tidak bisa ditulis sebagai RRULE)
+const calendarFeedDays = 365
+
+// newCalendarToken membuat token acak yang aman dipakai di URL
+func newCalendarToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GetCalendarToken mengambil token feed pengguna, dan membuatnya jika belum ada
+func (s *Store) GetCalendarToken(ctx context.Context, userID int64) (string, error) {
+	var token *string
+	err := s.Pool.QueryRow(ctx, `SELECT calendar_token FROM users WHERE id = $1`, userID).Scan(&token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrUserNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if token != nil {
+		return *token, nil
+	}
+	return s.RotateCalendarToken(ctx, userID)
+}
+
+// RotateCalendarToken mengganti token feed; URL lama langsung tidak berlaku
+func (s *Store) RotateCalendarToken(ctx context.Context, userID int64) (string, error) {
+	token, err := newCalendarToken()
+	if err != nil {
+		return "", err
+	}
+	ct, err := s.Pool.Exec(ctx, `UPDATE users SET calendar_token = $1 WHERE id = $2`, token, userID)
+	if err != nil {
+		return "", err
+	}
+	if ct.RowsAffected() == 0 {
+		return "", ErrUserNotFound
+	}
+	return token, nil
+}
+
+// DisableCalendarFeed menghapus token feed pengguna
+func (s *Store) DisableCalendarFeed(ctx context.Context, userID int64) error {
+	ct, err := s.Pool.Exec(ctx, `UPDATE users SET calendar_token = NULL WHERE id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// calendarTokenExists memeriksa apakah token feed milik salah satu pengguna
+func (s *Store) calendarTokenExists(ctx context.Context, token string) error {
+	var id int64
+	err := s.Pool.QueryRow(ctx, `SELECT id FROM users WHERE calendar_token = $1`, token).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// calendarRule menulis aturan jadwal sebagai RRULE yang berdiri sendiri dengan DTSTART =
+// next_due_date. Tanggal bulanan dari start_date ditulis eksplisit; tanggal 29-31 ditulis
+// sebagai "hari terakhir yang ada" agar bulan pendek tidak terlewat. Batas jadwal (UNTIL/COUNT
+// milik rrule, end_date dan max_occurrences) digabung menjadi satu UNTIL atau COUNT, dengan
+// COUNT dihitung ulang dari next_due_date.
+func calendarRule(rt models.RecurringTransaction) (string, error) {
+	base, err := scheduleRule(rt)
+	if err != nil {
+		return "", err
+	}
+	rule := *base
+	rule.Until, rule.Count = nil, 0
+
+	if (rule.Freq == recurrence.Monthly || rule.Freq == recurrence.Yearly) && len(rule.ByDay) == 0 && len(rule.ByMonthDay) == 0 {
+		if rule.Freq == recurrence.Yearly && len(rule.ByMonth) == 0 {
+			rule.ByMonth = []int{int(rt.StartDate.Month())}
+		}
+		if day := rt.StartDate.Day(); day <= 28 {
+			rule.ByMonthDay = []int{day}
+		} else {
+			for d := 28; d <= day; d++ {
+				rule.ByMonthDay = append(rule.ByMonthDay, d)
+			}
+			rule.BySetPos = []int{-1}
+		}
+	}
+
+	until := base.Until
+	if rt.EndDate != nil && (until == nil || dateKey(*rt.EndDate) < dateKey(*until)) {
+		until = rt.EndDate
+	}
+	count := 0
+	if base.Count > 0 {
+		count = base.Count - occurrencesBefore(base, rt.StartDate, rt.NextDueDate)
+	}
+	if rt.MaxOccurrences != nil {
+		if remaining := *rt.MaxOccurrences - rt.Occurrences; count == 0 || remaining < count {
+			count = remaining
+		}
+	}
+
+	// RRULE tidak boleh berisi UNTIL dan COUNT sekaligus: jika ada batas jumlah, hitung
+	// kejadian yang masih tersisa sampai batas tanggal dan tulis sebagai COUNT
+	switch {
+	case count > 0:
+		n, last := 1, rt.NextDueDate
+		for n < count {
+			next, ok := base.After(rt.StartDate, last)
+			if !ok || (until != nil && dateKey(next) > dateKey(*until)) {
+				break
+			}
+			n, last = n+1, next
+		}
+		rule.Count = n
+	case until != nil:
+		rule.Until = until
+	}
+	return rule.String(), nil
+}
+
+// recurringSummary menulis judul acara, misal "Listrik: -Rp 500.000,00"
+func recurringSummary(rt models.RecurringTransaction, accountNames map[int64]string) string {
+	switch rt.Type {
+	case "income":
+		return fmt.Sprintf("%s: +%s", rt.Category, exporter.FormatRupiah(rt.Amount))
+	case "transfer":
+		dest := ""
+		if rt.DestinationAccountID != nil {
+			dest = accountNames[*rt.DestinationAccountID]
+		}
+		return fmt.Sprintf("Transfer %s → %s: %s", accountNames[rt.AccountID], dest, exporter.FormatRupiah(rt.Amount))
+	}
+	return fmt.Sprintf("%s: -%s", rt.Category, exporter.FormatRupiah(rt.Amount))
+}
+
+// recurringDescription menulis detail acara: deskripsi jadwal dan akunnya
+func recurringDescription(rt models.RecurringTransaction, accountNames map[int64]string) string {
+	lines := []string{}
+	if rt.Description != "" {
+		lines = append(lines, rt.Description)
+	}
+	if rt.Type != "transfer" {
+		lines = append(lines, "Account: "+accountNames[rt.AccountID])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// uidPart membuat potongan UID yang hanya berisi huruf kecil, angka dan '-'
+func uidPart(s string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(s)), "-")
+}
+
+// BuildCalendar menyusun feed iCalendar: setiap jadwal berulang yang aktif sebagai satu acara
+// dengan RRULE, dan (jika includeBudgets) akhir periode budget bulan keuangan ini.
+func (s *Store) BuildCalendar(ctx context.Context, includeBudgets bool) (exporter.Calendar, error) {
+	cal := calendarFrom(ctx)
+	feed := exporter.Calendar{Name: "Finance Tracker", Timezone: cal.loc.String(), Now: time.Now(),
+		Events: make([]exporter.CalendarEvent, 0)}
+
+	accounts, err := s.GetAccounts(ctx)
+	if err != nil {
+		return feed, err
+	}
+	accountNames := make(map[int64]string, len(accounts))
+	for _, acc := range accounts {
+		accountNames[acc.ID] = acc.Name
+	}
+
+	rows, err := s.Pool.Query(ctx, `SELECT `+recurringColumns+` FROM recurring_transactions
+		WHERE `+recurringActiveClause+` ORDER BY next_due_date, id`)
+	if err != nil {
+		return feed, err
+	}
+	schedules, err := scanRecurring(rows)
+	if err != nil {
+		return feed, err
+	}
+
+	// 1. Jadwal berulang. Jadwal yang digeser ke hari kerja dijabarkan per kejadian.
+	shifted := make(map[int64]models.RecurringTransaction)
+	for _, rt := range schedules {
+		if rt.BusinessDayShift != models.ShiftNone {
+			shifted[rt.ID] = rt
+			continue
+		}
+		rule, err := calendarRule(rt)
+		if err != nil {
+			log.Printf("Skipping recurring transaction %d in calendar feed: %v", rt.ID, err)
+			continue
+		}
+		feed.Events = append(feed.Events, exporter.CalendarEvent{
+			UID:         fmt.Sprintf("recurring-%d@finance-tracker", rt.ID),
+			Date:        rt.NextDueDate,
+			Summary:     recurringSummary(rt, accountNames),
+			Description: recurringDescription(rt, accountNames),
+			RRule:       rule,
+		})
+	}
+	if len(shifted) > 0 {
+		occurrences, err := s.GetUpcomingOccurrences(ctx, cal.today().AddDate(0, 0, calendarFeedDays))
+		if err != nil {
+			return feed, err
+		}
+		for _, occ := range occurrences {
+			rt, ok := shifted[occ.RecurringID]
+			if !ok {
+				continue
+			}
+			feed.Events = append(feed.Events, exporter.CalendarEvent{
+				UID:         fmt.Sprintf("recurring-%d-%s@finance-tracker", rt.ID, occ.Date.Format("20060102")),
+				Date:        occ.Date,
+				Summary:     recurringSummary(rt, accountNames),
+				Description: recurringDescription(rt, accountNames),
+			})
+		}
+	}
+
+	// 2. Akhir periode budget bulan keuangan yang sedang berjalan
+	if includeBudgets {
+		month, year := cal.monthOf(time.Now())
+		progress, err := s.GetBudgetProgress(ctx, month, year)
+		if err != nil {
+			return feed, err
+		}
+		for _, p := range progress.Categories {
+			status := exporter.FormatRupiah(p.Remaining) + " left"
+			if p.Remaining < 0 {
+				status = exporter.FormatRupiah(-p.Remaining) + " over"
+			}
+			end := p.PeriodEnd.In(cal.loc)
+			feed.Events = append(feed.Events, exporter.CalendarEvent{
+				UID:         fmt.Sprintf("budget-%s-%s@finance-tracker", uidPart(p.CategoryName), end.Format("20060102")),
+				Date:        end,
+				Summary:     fmt.Sprintf("Budget period ends: %s (%s)", p.CategoryName, status),
+				Description: fmt.Sprintf("Spent %s of %s", exporter.FormatRupiah(p.Spent), exporter.FormatRupiah(p.Limit)),
+			})
+		}
+	}
+	return feed, nil
+}
+
+// calendarFeedURL menyusun URL feed lengkap dari host request
+func calendarFeedURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/api/calendar/%s.ics", scheme, r.Host, token)
+}
+
+// HandleGetCalendarFeed menangani GET /api/settings/calendar (butuh token).
+// Token feed dibuat saat pertama kali diminta.
+func (s *Store) HandleGetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	s.handleCalendarToken(w, r, s.GetCalendarToken)
+}
+
+// HandleRotateCalendarToken menangani POST /api/settings/calendar (butuh token)
+func (s *Store) HandleRotateCalendarToken(w http.ResponseWriter, r *http.Request) {
+	s.handleCalendarToken(w, r, s.RotateCalendarToken)
+}
+
+func (s *Store) handleCalendarToken(w http.ResponseWriter, r *http.Request,
+	get func(context.Context, int64) (string, error)) {
+	userID, err := s.userIDFromRequest(r)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Invalid or missing token")
+		return
+	}
+
+	token, err := get(r.Context(), userID)
+	if errors.Is(err, ErrUserNotFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, models.CalendarFeed{Token: token, URL: calendarFeedURL(r, token)})
+}
+
+// HandleDisableCalendarFeed menangani DELETE /api/settings/calendar (butuh token)
+func (s *Store) HandleDisableCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.userIDFromRequest(r)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Invalid or missing token")
+		return
+	}
+
+	err = s.DisableCalendarFeed(r.Context(), userID)
+	if errors.Is(err, ErrUserNotFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleCalendarFeed menangani GET /api/calendar/{token}.ics?budgets=true. Aplikasi kalender
+// tidak mengirim header Authorization, jadi token di URL yang menjadi kuncinya.
+func (s *Store) HandleCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := s.calendarTokenExists(ctx, mux.Vars(r)["token"])
+	if errors.Is(err, ErrUserNotFound) {
+		respondError(w, http.StatusNotFound, "Calendar feed not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	includeBudgets, _ := strconv.ParseBool(r.URL.Query().Get("budgets"))
+	feed, err := s.BuildCalendar(ctx, includeBudgets)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="finance-tracker.ics"`)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	if err := exporter.WriteICal(w, feed); err != nil {
+		log.Printf("Failed to write calendar feed: %v", err)
+	}
+}
@@ -0,0 +1,534 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/importer"
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+const (
+	maxImportFileSize = 10 << 20 // 10 MB
+	previewRowLimit   = 100      // Jumlah baris yang dikirim di preview
+)
+
+// readImportUpload membaca file dari form multipart dengan field "file"
+func readImportUpload(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize)
+	if err := r.ParseMultipartForm(maxImportFileSize); err != nil {
+		return nil, errors.New("file is too large or the form is invalid")
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, errors.New("missing 'file' in form data")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, errors.New("failed to read uploaded file")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("uploaded file is empty")
+	}
+	return data, nil
+}
+
+// parseFormAccountID membaca account_id dari form, 0 jika tidak diisi
+func parseFormAccountID(r *http.Request) (int64, error) {
+	idStr := r.FormValue("account_id")
+	if idStr == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid account_id")
+	}
+	return id, nil
+}
+
+// duplicateKey mengidentifikasi transaksi berdasarkan tanggal, nominal, dan deskripsi.
+// Tanggal diformat sama persis seperti di HandleExportCSV.
+func duplicateKey(date time.Time, amount int64, description string) string {
+	return fmt.Sprintf("%s|%d|%s", date.Format("2006-01-02"), amount, strings.ToLower(strings.TrimSpace(description)))
+}
+
+// markDuplicates menandai baris yang sudah ada di tabel transactions.
+// Baris dengan Reference (FITID, no. referensi bank) dicocokkan dengan external_id
+// milik accountID. Baris lain dicocokkan berdasarkan tanggal/nominal/deskripsi;
+// jika file berisi 2 baris identik tapi database baru punya 1, hanya 1 yang ditandai.
+// Tanggal dari file bank tidak punya zona waktu, jadi sekalian dipindah ke zona waktu aplikasi.
+func (s *Store) markDuplicates(ctx context.Context, rows []models.ImportRow, accountID int64) error {
+	cal := calendarFrom(ctx)
+	for i := range rows {
+		if !rows[i].Date.IsZero() {
+			rows[i].Date = cal.atDate(rows[i].Date)
+		}
+	}
+
+	var minDate, maxDate time.Time
+	var references []string
+	for _, row := range rows {
+		if row.Error != "" || row.Skipped != "" {
+			continue
+		}
+		if minDate.IsZero() || row.Date.Before(minDate) {
+			minDate = row.Date
+		}
+		if row.Date.After(maxDate) {
+			maxDate = row.Date
+		}
+		if row.Reference != "" {
+			references = append(references, row.Reference)
+		}
+	}
+	if minDate.IsZero() {
+		return nil
+	}
+
+	// 1. Referensi bank yang sudah pernah diimpor ke akun ini
+	knownRefs := make(map[string]bool)
+	if len(references) > 0 && accountID > 0 {
+		refRows, err := s.Pool.Query(ctx,
+			`SELECT external_id FROM transactions WHERE account_id = $1 AND external_id = ANY($2)`,
+			accountID, references)
+		if err != nil {
+			return err
+		}
+		for refRows.Next() {
+			var ref string
+			if err := refRows.Scan(&ref); err != nil {
+				refRows.Close()
+				return err
+			}
+			knownRefs[ref] = true
+		}
+		refRows.Close()
+		if err := refRows.Err(); err != nil {
+			return err
+		}
+	}
+
+	// 2. Transaksi di rentang tanggal yang sama.
+	// Rentang diperlebar 1 hari agar aman dari perbedaan zona waktu.
+	query := `
+		SELECT date, amount, COALESCE(description, ''), external_id IS NOT NULL
+		FROM transactions
+		WHERE date >= $1 AND date < $2`
+
+	dbRows, err := s.Pool.Query(ctx, query, minDate.AddDate(0, 0, -1), maxDate.AddDate(0, 0, 2))
+	if err != nil {
+		return err
+	}
+	defer dbRows.Close()
+
+	existing := make(map[string]int)
+	existingWithoutRef := make(map[string]int) // Hanya transaksi yang diinput manual / dari CSV
+	for dbRows.Next() {
+		var date time.Time
+		var amount int64
+		var description string
+		var hasRef bool
+		if err := dbRows.Scan(&date, &amount, &description, &hasRef); err != nil {
+			return err
+		}
+		key := duplicateKey(date.In(cal.loc), amount, description)
+		existing[key]++
+		if !hasRef {
+			existingWithoutRef[key]++
+		}
+	}
+	if err := dbRows.Err(); err != nil {
+		return err
+	}
+
+	seenRefs := make(map[string]bool)
+	for i := range rows {
+		if rows[i].Error != "" || rows[i].Skipped != "" {
+			continue
+		}
+		key := duplicateKey(rows[i].Date, rows[i].Amount, rows[i].Description)
+
+		if ref := rows[i].Reference; ref != "" {
+			// Referensi yang sama di database atau di file ini sendiri
+			if knownRefs[ref] || seenRefs[ref] {
+				rows[i].Duplicate = true
+			} else if existingWithoutRef[key] > 0 {
+				rows[i].Duplicate = true
+				existingWithoutRef[key]--
+			}
+			seenRefs[ref] = true
+			continue
+		}
+
+		if existing[key] > 0 {
+			rows[i].Duplicate = true
+			existing[key]--
+		}
+	}
+	return nil
+}
+
+// buildImportPreview menandai duplikat lalu menghitung ringkasan preview
+func (s *Store) buildImportPreview(ctx context.Context, rows []models.ImportRow, accountID int64) (models.ImportPreview, error) {
+	if err := s.markDuplicates(ctx, rows, accountID); err != nil {
+		return models.ImportPreview{}, err
+	}
+
+	preview := models.ImportPreview{TotalRows: len(rows)}
+	for _, row := range rows {
+		if row.Error != "" {
+			preview.ErrorCount++
+		} else if row.Skipped != "" {
+			preview.SkippedCount++
+		} else if row.Duplicate {
+			preview.DuplicateCount++
+		}
+	}
+
+	if len(rows) > previewRowLimit {
+		rows = rows[:previewRowLimit]
+	}
+	preview.Rows = rows
+	return preview, nil
+}
+
+// CommitImportRows menyimpan baris hasil impor melalui CreateTransactionsBatch.
+// Baris duplikat dilewati. Baris tanpa nama akun memakai defaultAccountID.
+func (s *Store) CommitImportRows(ctx context.Context, rows []models.ImportRow, defaultAccountID int64) (models.ImportResult, error) {
+	var result models.ImportResult
+
+	if err := s.markDuplicates(ctx, rows, defaultAccountID); err != nil {
+		return result, err
+	}
+
+	accounts, err := s.GetAccounts(ctx)
+	if err != nil {
+		return result, err
+	}
+	accountByName := make(map[string]int64, len(accounts))
+	for _, acc := range accounts {
+		accountByName[strings.ToLower(strings.TrimSpace(acc.Name))] = acc.ID
+	}
+	resolveAccount := func(name string) (int64, error) {
+		if name == "" {
+			if defaultAccountID <= 0 {
+				return 0, errors.New("no account selected for this row")
+			}
+			return defaultAccountID, nil
+		}
+		id, ok := accountByName[strings.ToLower(name)]
+		if !ok {
+			return 0, fmt.Errorf("unknown account %q", name)
+		}
+		return id, nil
+	}
+
+	// 1. Ubah setiap baris menjadi transaksi, kumpulkan SEMUA error
+	var rowErrors []models.BatchRowError
+	txs := make([]models.Transaction, 0, len(rows))
+	rowIndexes := make([]int, 0, len(rows)) // Indeks baris impor untuk setiap elemen txs
+	for i, row := range rows {
+		if row.Error != "" {
+			rowErrors = append(rowErrors, models.BatchRowError{Index: i, Line: row.Line, Error: row.Error})
+			continue
+		}
+		if row.Skipped != "" {
+			result.SkippedRows++
+			continue
+		}
+		if row.Duplicate {
+			result.SkippedDuplicates++
+			continue
+		}
+
+		accountID, err := resolveAccount(row.AccountName)
+		if err != nil {
+			rowErrors = append(rowErrors, models.BatchRowError{Index: i, Line: row.Line, Error: err.Error()})
+			continue
+		}
+		txData := models.Transaction{
+			Amount:      row.Amount,
+			Type:        row.Type,
+			Category:    row.Category,
+			Description: row.Description,
+			Date:        row.Date,
+			AccountID:   accountID,
+		}
+		if row.Reference != "" {
+			ref := row.Reference
+			txData.ExternalID = &ref
+		}
+		if row.Type == "transfer" {
+			destID, err := resolveAccount(row.DestinationAccountName)
+			if err != nil {
+				rowErrors = append(rowErrors, models.BatchRowError{Index: i, Line: row.Line, Error: err.Error()})
+				continue
+			}
+			txData.DestinationAccountID = &destID
+		}
+		txs = append(txs, txData)
+		rowIndexes = append(rowIndexes, i)
+	}
+
+	if len(rowErrors) > 0 {
+		return result, &BatchValidationError{Rows: rowErrors}
+	}
+	if len(txs) == 0 {
+		return result, nil // Semua baris adalah duplikat
+	}
+
+	// 2. Simpan sekaligus
+	created, err := s.CreateTransactionsBatch(ctx, txs)
+	if err != nil {
+		var batchErr *BatchValidationError
+		if errors.As(err, &batchErr) {
+			// Kembalikan indeks baris impor & nomor baris file, bukan indeks array transaksi
+			for i := range batchErr.Rows {
+				idx := rowIndexes[batchErr.Rows[i].Index]
+				batchErr.Rows[i].Index, batchErr.Rows[i].Line = idx, rows[idx].Line
+			}
+		}
+		return result, err
+	}
+	result.Imported = created
+	return result, nil
+}
+
+// readCSVImport membaca file dan mapping dari form. Jika field "mapping" tidak
+// dikirim, mapping hasil deteksi otomatis yang dipakai.
+func readCSVImport(w http.ResponseWriter, r *http.Request) (models.CSVMapping, [][]string, []string, error) {
+	data, err := readImportUpload(w, r)
+	if err != nil {
+		return models.CSVMapping{}, nil, nil, err
+	}
+
+	mapping, records, err := importer.SuggestMapping(data)
+	if err != nil {
+		return mapping, nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+
+	if mappingJSON := r.FormValue("mapping"); mappingJSON != "" {
+		var custom models.CSVMapping
+		if err := json.Unmarshal([]byte(mappingJSON), &custom); err != nil {
+			return mapping, nil, nil, errors.New("invalid mapping JSON")
+		}
+		delimiter, err := importer.ParseDelimiter(custom.Delimiter)
+		if err != nil {
+			return mapping, nil, nil, err
+		}
+		if records, err = importer.ReadCSV(data, delimiter); err != nil {
+			return mapping, nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		mapping = custom
+	}
+
+	if err := importer.ValidateMapping(mapping); err != nil {
+		return mapping, records, nil, err
+	}
+
+	var headers []string
+	if mapping.HasHeader {
+		headers = records[0]
+	}
+	return mapping, records, headers, nil
+}
+
+// HandlePreviewCSVImport menangani POST /api/import/csv/preview
+func (s *Store) HandlePreviewCSVImport(w http.ResponseWriter, r *http.Request) {
+	mapping, records, headers, err := readCSVImport(w, r)
+	if err != nil {
+		// Tetap kirim mapping hasil deteksi agar client bisa memperbaikinya
+		respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":   err.Error(),
+			"mapping": mapping,
+		})
+		return
+	}
+
+	accountID, err := parseFormAccountID(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rows := importer.ParseCSV(records, mapping)
+	preview, err := s.buildImportPreview(r.Context(), rows, accountID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	preview.Mapping = &mapping
+	preview.Headers = headers
+
+	respondJSON(w, http.StatusOK, preview)
+}
+
+// HandleCommitCSVImport menangani POST /api/import/csv
+func (s *Store) HandleCommitCSVImport(w http.ResponseWriter, r *http.Request) {
+	mapping, records, _, err := readCSVImport(w, r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	accountID, err := parseFormAccountID(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rows := importer.ParseCSV(records, mapping)
+	if len(rows) > MaxBatchTransactions {
+		respondError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("File cannot contain more than %d transactions", MaxBatchTransactions))
+		return
+	}
+
+	result, err := s.CommitImportRows(r.Context(), rows, accountID)
+	if err != nil {
+		respondBatchError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, result)
+}
+
+// getAccountBalance mengambil current_balance satu akun
+func (s *Store) getAccountBalance(ctx context.Context, accountID int64) (int64, error) {
+	var balance int64
+	err := s.Pool.QueryRow(ctx, `SELECT current_balance FROM accounts WHERE id = $1`, accountID).Scan(&balance)
+	return balance, err
+}
+
+// compareStatementBalance membandingkan saldo akhir di file bank dengan current_balance akun
+func (s *Store) compareStatementBalance(ctx context.Context, stmt *importer.Statement, accountID int64) (*models.StatementBalance, error) {
+	if stmt.LedgerBalance == nil {
+		return nil, nil
+	}
+	current, err := s.getAccountBalance(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.StatementBalance{
+		Currency:       stmt.Currency,
+		LedgerBalance:  *stmt.LedgerBalance,
+		LedgerDate:     stmt.LedgerDate,
+		CurrentBalance: current,
+		Difference:     *stmt.LedgerBalance - current,
+	}, nil
+}
+
+// readStatementImport membaca file mutasi dan account_id (wajib) dari form.
+// Jika format kosong, format diambil dari field "format" atau dideteksi otomatis.
+func readStatementImport(w http.ResponseWriter, r *http.Request, format string) (*importer.Statement, int64, error) {
+	data, err := readImportUpload(w, r)
+	if err != nil {
+		return nil, 0, err
+	}
+	accountID, err := parseFormAccountID(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	if accountID == 0 {
+		return nil, 0, errors.New("account_id is required")
+	}
+
+	if format == "" {
+		format = r.FormValue("format")
+	}
+	var imp importer.StatementImporter
+	if format == "" {
+		imp, err = importer.Detect(data)
+	} else {
+		imp, err = importer.Lookup(format)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w, supported formats: %s", err, strings.Join(importer.Formats(), ", "))
+	}
+
+	stmt, err := imp.Parse(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse %s statement: %w", imp.Name(), err)
+	}
+	return stmt, accountID, nil
+}
+
+func (s *Store) previewStatementImport(w http.ResponseWriter, r *http.Request, format string) {
+	stmt, accountID, err := readStatementImport(w, r, format)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	preview, err := s.buildImportPreview(r.Context(), stmt.Rows, accountID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if preview.Balance, err = s.compareStatementBalance(r.Context(), stmt, accountID); err != nil {
+		respondError(w, http.StatusBadRequest, "Account not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, preview)
+}
+
+func (s *Store) commitStatementImport(w http.ResponseWriter, r *http.Request, format string) {
+	stmt, accountID, err := readStatementImport(w, r, format)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(stmt.Rows) > MaxBatchTransactions {
+		respondError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("File cannot contain more than %d transactions", MaxBatchTransactions))
+		return
+	}
+
+	result, err := s.CommitImportRows(r.Context(), stmt.Rows, accountID)
+	if err != nil {
+		respondBatchError(w, err)
+		return
+	}
+
+	// Bandingkan saldo SETELAH impor
+	if result.Balance, err = s.compareStatementBalance(r.Context(), stmt, accountID); err != nil {
+		log.Printf("Error comparing statement balance: %v", err)
+	}
+	respondJSON(w, http.StatusCreated, result)
+}
+
+// HandlePreviewStatementImport menangani POST /api/import/statement/preview
+func (s *Store) HandlePreviewStatementImport(w http.ResponseWriter, r *http.Request) {
+	s.previewStatementImport(w, r, "")
+}
+
+// HandleCommitStatementImport menangani POST /api/import/statement
+func (s *Store) HandleCommitStatementImport(w http.ResponseWriter, r *http.Request) {
+	s.commitStatementImport(w, r, "")
+}
+
+// HandlePreviewOFXImport menangani POST /api/import/ofx/preview
+func (s *Store) HandlePreviewOFXImport(w http.ResponseWriter, r *http.Request) {
+	s.previewStatementImport(w, r, "ofx")
+}
+
+// HandleCommitOFXImport menangani POST /api/import/ofx
+func (s *Store) HandleCommitOFXImport(w http.ResponseWriter, r *http.Request) {
+	s.commitStatementImport(w, r, "ofx")
+}
+
+// HandleGetImportFormats menangani GET /api/import/formats
+func (s *Store) HandleGetImportFormats(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, map[string][]string{"formats": importer.Formats()})
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// insertNotification menyimpan notifikasi in-app di dalam transaksi yang sedang berjalan
+func insertNotification(ctx context.Context, tx pgx.Tx, n *models.Notification) error {
+	return tx.QueryRow(ctx, `
+		INSERT INTO notifications (type, title, message, data)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		n.Type, n.Title, n.Message, string(n.Data), // string agar tidak dikirim sebagai bytea
+	).Scan(&n.ID, &n.CreatedAt)
+}
+
+// GetNotifications mengambil notifikasi terbaru lebih dulu, beserta jumlah yang belum dibaca
+func (s *Store) GetNotifications(ctx context.Context, unreadOnly bool, limit int) (*models.NotificationList, error) {
+	query := `SELECT id, type, title, message, data, read_at, created_at FROM notifications`
+	if unreadOnly {
+		query += ` WHERE read_at IS NULL`
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT $1`
+
+	rows, err := s.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := &models.NotificationList{Notifications: make([]models.Notification, 0)}
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.Type, &n.Title, &n.Message, &n.Data, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		list.Notifications = append(list.Notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = s.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM notifications WHERE read_at IS NULL`).Scan(&list.UnreadCount)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *Store) MarkNotificationRead(ctx context.Context, id int64) error {
+	ct, err := s.Pool.Exec(ctx, `UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (s *Store) MarkAllNotificationsRead(ctx context.Context) (int64, error) {
+	ct, err := s.Pool.Exec(ctx, `UPDATE notifications SET read_at = NOW() WHERE read_at IS NULL`)
+	if err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
+
+// HandleGetNotifications menangani GET /api/notifications?unread=true&limit=50
+func (s *Store) HandleGetNotifications(w http.ResponseWriter, r *http.Request) {
+	unreadOnly, _ := strconv.ParseBool(r.URL.Query().Get("unread"))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	list, err := s.GetNotifications(r.Context(), unreadOnly, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, list)
+}
+
+// HandleMarkNotificationRead menangani POST /api/notifications/{id}/read
+func (s *Store) HandleMarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromVars(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.MarkNotificationRead(r.Context(), id); err != nil {
+		if errors.Is(err, ErrNotificationNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleMarkAllNotificationsRead menangani POST /api/notifications/read-all
+func (s *Store) HandleMarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	updated, err := s.MarkAllNotificationsRead(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]int64{"updated": updated})
+}
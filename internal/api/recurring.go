@@ -0,0 +1,516 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+	"github.com/bramszs/finance-tracker/internal/recurrence"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrRecurringNotFound = errors.New("recurring transaction not found")
+	ErrRecurringFinished = errors.New("recurring transaction has already finished")
+)
+
+// recurringActiveClause memilih jadwal yang tidak dijeda dan belum berakhir
+const recurringActiveClause = `paused_at IS NULL
+	AND (end_date IS NULL OR next_due_date <= end_date)
+	AND (max_occurrences IS NULL OR occurrences < max_occurrences)`
+
+// ErrNoOccurrences dikembalikan jika aturan jadwal tidak punya kejadian lagi
+var ErrNoOccurrences = errors.New("recurrence rule has no further occurrences")
+
+// normalizeRecurrenceRule memvalidasi rrule & business_day_shift, lalu frequency & interval
+// disalin dari aturannya. COUNT dan UNTIL tetap di rrule: keduanya berlaku untuk tanggal
+// kejadian sebelum digeser ke hari kerja, terpisah dari max_occurrences dan end_date.
+func normalizeRecurrenceRule(rt *models.RecurringTransaction) error {
+	switch rt.BusinessDayShift {
+	case "":
+		rt.BusinessDayShift = models.ShiftNone
+	case models.ShiftNone, models.ShiftPrevious, models.ShiftNext:
+	default:
+		return errors.New("business_day_shift must be none, previous or next")
+	}
+	if rt.RRule == "" {
+		return nil
+	}
+
+	rule, err := recurrence.Parse(rt.RRule)
+	if err != nil {
+		return err
+	}
+	rt.RRule = rule.String()
+	rt.Frequency, rt.Interval = strings.ToLower(rule.Freq), rule.Interval
+	return nil
+}
+
+// occurrencesBefore menghitung kejadian aturan (mulai start) yang jatuh sebelum tanggal date
+func occurrencesBefore(rule *recurrence.Rule, start, date time.Time) int {
+	n := 0
+	for d := start.AddDate(0, 0, -1); ; n++ {
+		next, ok := rule.After(start, d)
+		if !ok || dateKey(next) >= dateKey(date) {
+			return n
+		}
+		d = next
+	}
+}
+
+// reanchorRecurring menjadikan next_due_date yang diubah sebagai start_date baru. COUNT di
+// rrule dihitung dari start_date, jadi kejadian sebelum patokan baru dikurangi dari COUNT
+// agar deretnya tidak bertambah panjang.
+func reanchorRecurring(rt *models.RecurringTransaction) error {
+	if rt.RRule != "" {
+		rule, err := recurrence.Parse(rt.RRule)
+		if err != nil {
+			return err
+		}
+		if rule.Count > 0 {
+			passed := occurrencesBefore(rule, rt.StartDate, rt.NextDueDate)
+			if passed >= rule.Count {
+				return errors.New("next_due_date is after the last occurrence allowed by the rrule COUNT")
+			}
+			rule.Count -= passed
+			rt.RRule = rule.String()
+		}
+	}
+	rt.StartDate = rt.NextDueDate
+	return nil
+}
+
+// scheduleRule mengembalikan aturan pengulangan jadwal: rrule-nya, atau aturan sederhana
+// dari frequency & interval. Jadwal bulanan & tahunan dihitung dari tanggal start_date,
+// sehingga jadwal tanggal 31 jatuh di hari terakhir bulan pendek lalu kembali ke tanggal 31.
+func scheduleRule(rt models.RecurringTransaction) (*recurrence.Rule, error) {
+	if rt.RRule != "" {
+		return recurrence.Parse(rt.RRule)
+	}
+	if !recurringFrequencies[rt.Frequency] {
+		return nil, fmt.Errorf("unknown frequency %q", rt.Frequency)
+	}
+	if rt.Interval < 1 {
+		return nil, fmt.Errorf("invalid interval %d", rt.Interval)
+	}
+	return &recurrence.Rule{Freq: strings.ToUpper(rt.Frequency), Interval: rt.Interval}, nil
+}
+
+// firstOccurrence menghitung jatuh tempo pertama jadwal baru: kejadian pertama mulai
+// start_date (sudah digeser ke hari kerja jika perlu)
+func firstOccurrence(rt models.RecurringTransaction, holidays holidaySet) (time.Time, error) {
+	rule, err := scheduleRule(rt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	first, ok := rule.After(rt.StartDate, rt.StartDate.AddDate(0, 0, -1))
+	if !ok {
+		return time.Time{}, ErrNoOccurrences
+	}
+	return holidays.shift(first, rt.BusinessDayShift), nil
+}
+
+// nextOccurrence menghitung jatuh tempo pertama SETELAH from (sudah digeser ke hari kerja).
+// Kejadian yang setelah digeser jatuh di from atau sebelumnya dilewati, agar tidak diposting dua kali.
+func nextOccurrence(rt models.RecurringTransaction, from time.Time, holidays holidaySet) (time.Time, error) {
+	rule, err := scheduleRule(rt)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	nominal := from
+	for range 366 {
+		next, ok := rule.After(rt.StartDate, nominal)
+		if !ok {
+			break
+		}
+		if due := holidays.shift(next, rt.BusinessDayShift); dateKey(due) > dateKey(from) {
+			return due, nil
+		}
+		nominal = next
+	}
+	return time.Time{}, ErrNoOccurrences
+}
+
+// advanceRecurring memajukan jadwal setelah kejadian yang jatuh tempo diposting atau dilewati.
+// Kejadian itu dihitung ke occurrences; jika aturannya tidak punya kejadian lagi, jadwal
+// diselesaikan dengan membatasi max_occurrences.
+func advanceRecurring(rt *models.RecurringTransaction, holidays holidaySet) error {
+	rt.Occurrences++
+	next, err := nextOccurrence(*rt, rt.NextDueDate, holidays)
+	switch {
+	case errors.Is(err, ErrNoOccurrences):
+		done := rt.Occurrences
+		rt.MaxOccurrences = &done
+	case err != nil:
+		return err
+	default:
+		rt.NextDueDate = next
+	}
+	setRecurringStatus(rt)
+	return nil
+}
+
+// dateKey menulis tanggal kalender t (di zonanya sendiri) agar kolom DATE yang dibaca
+// sebagai UTC bisa dibandingkan dengan tanggal di zona waktu aplikasi
+func dateKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// setRecurringStatus mengisi Status dengan aturan yang sama dengan recurringActiveClause
+func setRecurringStatus(rt *models.RecurringTransaction) {
+	switch {
+	case rt.EndDate != nil && dateKey(rt.NextDueDate) > dateKey(*rt.EndDate),
+		rt.MaxOccurrences != nil && rt.Occurrences >= *rt.MaxOccurrences:
+		rt.Status = models.RecurringFinished
+	case rt.PausedAt != nil:
+		rt.Status = models.RecurringPaused
+	default:
+		rt.Status = models.RecurringActive
+	}
+}
+
+// GetRecurringTransaction mengambil satu jadwal berdasarkan ID
+func (s *Store) GetRecurringTransaction(ctx context.Context, id int64) (*models.RecurringTransaction, error) {
+	rows, err := s.Pool.Query(ctx, `SELECT `+recurringColumns+` FROM recurring_transactions WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	list, err := scanRecurring(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrRecurringNotFound
+	}
+	return &list[0], nil
+}
+
+// UpdateRecurringTransaction menyimpan semua kolom yang bisa diubah pengguna.
+// occurrences dan paused_at hanya diubah oleh worker dan endpoint pause/resume.
+func (s *Store) UpdateRecurringTransaction(ctx context.Context, rt *models.RecurringTransaction) error {
+	ct, err := s.Pool.Exec(ctx, `
+		UPDATE recurring_transactions
+		SET amount = $1, type = $2, category = $3, description = $4, account_id = $5,
+			destination_account_id = $6, frequency = $7, "interval" = $8, rrule = NULLIF($9, ''),
+			business_day_shift = $10, start_date = $11, next_due_date = $12, end_date = $13, max_occurrences = $14
+		WHERE id = $15`,
+		rt.Amount, rt.Type, rt.Category, rt.Description, rt.AccountID, rt.DestinationAccountID,
+		rt.Frequency, rt.Interval, rt.RRule, rt.BusinessDayShift, rt.StartDate, rt.NextDueDate,
+		rt.EndDate, rt.MaxOccurrences, rt.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrRecurringNotFound
+	}
+	setRecurringStatus(rt)
+	return nil
+}
+
+// PauseRecurringTransaction menghentikan sementara jadwal. Menjeda ulang tidak mengubah paused_at.
+func (s *Store) PauseRecurringTransaction(ctx context.Context, id int64) (*models.RecurringTransaction, error) {
+	ct, err := s.Pool.Exec(ctx, `UPDATE recurring_transactions SET paused_at = COALESCE(paused_at, NOW()) WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if ct.RowsAffected() == 0 {
+		return nil, ErrRecurringNotFound
+	}
+	return s.GetRecurringTransaction(ctx, id)
+}
+
+// ResumeRecurringTransaction menjalankan lagi jadwal yang dijeda. Kejadian yang jatuh tempo
+// selama dijeda dilewati, jadi jatuh tempo berikutnya paling cepat hari ini.
+func (s *Store) ResumeRecurringTransaction(ctx context.Context, id int64) (*models.RecurringTransaction, error) {
+	rt, err := s.GetRecurringTransaction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rt.PausedAt == nil {
+		return rt, nil
+	}
+	holidays, err := s.loadHolidays(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	today := dateKey(calendarFrom(ctx).today())
+	for dateKey(rt.NextDueDate) < today {
+		next, err := nextOccurrence(*rt, rt.NextDueDate, holidays)
+		if errors.Is(err, ErrNoOccurrences) {
+			// Aturannya (misal UNTIL) sudah habis selama dijeda: jadwal selesai
+			done := rt.Occurrences
+			rt.MaxOccurrences = &done
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rt.NextDueDate = next
+	}
+
+	_, err = s.Pool.Exec(ctx, `
+		UPDATE recurring_transactions SET paused_at = NULL, next_due_date = $1, max_occurrences = $2
+		WHERE id = $3`, rt.NextDueDate, rt.MaxOccurrences, id)
+	if err != nil {
+		return nil, err
+	}
+	rt.PausedAt = nil
+	setRecurringStatus(rt)
+	return rt, nil
+}
+
+// SkipRecurringOccurrence melewati kejadian berikutnya tanpa memposting transaksi. Seperti
+// kejadian yang diposting, kejadian yang dilewati dihitung ke max_occurrences, dan jadwal
+// selesai jika kejadian berikutnya lewat dari end_date.
+func (s *Store) SkipRecurringOccurrence(ctx context.Context, id int64) (*models.RecurringTransaction, error) {
+	rt, err := s.GetRecurringTransaction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rt.Status == models.RecurringFinished {
+		return nil, ErrRecurringFinished
+	}
+	holidays, err := s.loadHolidays(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := advanceRecurring(rt, holidays); err != nil {
+		return nil, err
+	}
+	_, err = s.Pool.Exec(ctx, `
+		UPDATE recurring_transactions SET next_due_date = $1, occurrences = $2, max_occurrences = $3
+		WHERE id = $4`, rt.NextDueDate, rt.Occurrences, rt.MaxOccurrences, id)
+	if err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// recurringPayload membaca body jadwal berulang. Tanggalnya disimpan sebagai DATE, jadi dibaca
+// lewat calendar.parseTime seperti transactionPayload: "2026-03-01" tetap tanggal 1 Maret di
+// zona waktu mana pun. Field tanggal yang tidak dikirim tidak mengubah nilai di jadwal.
+type recurringPayload struct {
+	*models.RecurringTransaction
+	StartDate   json.RawMessage `json:"start_date"`
+	NextDueDate json.RawMessage `json:"next_due_date"`
+	EndDate     json.RawMessage `json:"end_date"`
+}
+
+// decodeRecurring membaca body request ke rt, yang untuk PATCH sudah berisi nilai lama
+func decodeRecurring(r *http.Request, rt *models.RecurringTransaction) error {
+	payload := recurringPayload{RecurringTransaction: rt}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return errors.New("Invalid request payload")
+	}
+
+	cal := calendarFrom(r.Context())
+	parse := func(name string, raw json.RawMessage) (*time.Time, error) {
+		var value *string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("%s must be a date string", name)
+		}
+		if value == nil || *value == "" {
+			return nil, nil // null atau "" mengosongkan field
+		}
+		t, err := cal.parseTime(*value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s format, use yyyy-mm-dd or RFC 3339", name)
+		}
+		return &t, nil
+	}
+
+	if payload.StartDate != nil {
+		t, err := parse("start_date", payload.StartDate)
+		if err != nil {
+			return err
+		}
+		rt.StartDate = time.Time{}
+		if t != nil {
+			rt.StartDate = *t
+		}
+	}
+	if payload.NextDueDate != nil {
+		t, err := parse("next_due_date", payload.NextDueDate)
+		if err != nil {
+			return err
+		}
+		rt.NextDueDate = time.Time{}
+		if t != nil {
+			rt.NextDueDate = *t
+		}
+	}
+	if payload.EndDate != nil {
+		t, err := parse("end_date", payload.EndDate)
+		if err != nil {
+			return err
+		}
+		rt.EndDate = t
+	}
+	return nil
+}
+
+// scheduleChanged melaporkan apakah kolom yang menentukan tanggal kejadian ikut diubah
+func scheduleChanged(rt, old models.RecurringTransaction) bool {
+	return rt.RRule != old.RRule || rt.Frequency != old.Frequency || rt.Interval != old.Interval ||
+		rt.BusinessDayShift != old.BusinessDayShift || dateKey(rt.StartDate) != dateKey(old.StartDate)
+}
+
+// HandleUpdateRecurringTransaction menangani PUT dan PATCH /api/recurring/{id}.
+// PUT mengganti semua kolom yang bisa diubah (start_date & next_due_date boleh dikosongkan),
+// PATCH hanya mengubah kolom yang dikirim.
+func (s *Store) HandleUpdateRecurringTransaction(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromVars(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx := r.Context()
+
+	old, err := s.GetRecurringTransaction(ctx, id)
+	if err != nil {
+		respondRecurringError(w, err)
+		return
+	}
+
+	rt := *old
+	if r.Method == http.MethodPut {
+		rt = models.RecurringTransaction{}
+	}
+	if err := decodeRecurring(r, &rt); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Kolom yang tidak bisa diubah lewat endpoint ini
+	rt.ID, rt.Occurrences, rt.PausedAt, rt.CreatedAt = old.ID, old.Occurrences, old.PausedAt, old.CreatedAt
+	rt.LastError, rt.LastErrorAt = old.LastError, old.LastErrorAt
+	if rt.StartDate.IsZero() {
+		rt.StartDate = old.StartDate
+	}
+	if rt.NextDueDate.IsZero() {
+		rt.NextDueDate = old.NextDueDate
+	}
+	// frequency & interval disalin dari rrule, jadi mengubahnya tanpa mengubah rrule tidak berpengaruh
+	if rt.RRule != "" && rt.RRule == old.RRule && ((rt.Frequency != "" && !strings.EqualFold(rt.Frequency, old.Frequency)) ||
+		(rt.Interval != 0 && rt.Interval != old.Interval)) {
+		respondError(w, http.StatusBadRequest, "frequency and interval come from the rrule; change or clear the rrule instead")
+		return
+	}
+	if rt.Interval == 0 {
+		rt.Interval = 1
+	}
+
+	if err := validateRecurring(&rt); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Kejadian berikutnya dihitung dari start_date, jadi next_due_date yang diubah menjadi
+	// patokan baru; tanpa itu jadwal kembali ke tanggal start_date setelah diposting. Jika
+	// start_date juga diubah, next_due_date harus jatuh pada salah satu kejadiannya.
+	nextDueChanged := dateKey(rt.NextDueDate) != dateKey(old.NextDueDate)
+	if nextDueChanged && dateKey(rt.StartDate) == dateKey(old.StartDate) {
+		if err := reanchorRecurring(&rt); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else if nextDueChanged {
+		holidays, err := s.loadHolidays(ctx)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		due, err := nextOccurrence(rt, rt.NextDueDate.AddDate(0, 0, -1), holidays)
+		if err != nil && !errors.Is(err, ErrNoOccurrences) {
+			respondRecurringError(w, err)
+			return
+		}
+		if dateKey(due) != dateKey(rt.NextDueDate) {
+			respondError(w, http.StatusBadRequest, "next_due_date is not an occurrence of the schedule starting at start_date")
+			return
+		}
+	}
+
+	// Aturan berubah tapi next_due_date tidak dikirim: hitung ulang mulai jatuh tempo lama
+	if !nextDueChanged && scheduleChanged(rt, *old) {
+		holidays, err := s.loadHolidays(ctx)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if dateKey(rt.StartDate) > dateKey(old.NextDueDate) {
+			rt.NextDueDate, err = firstOccurrence(rt, holidays)
+		} else {
+			rt.NextDueDate, err = nextOccurrence(rt, old.NextDueDate.AddDate(0, 0, -1), holidays)
+		}
+		if err != nil {
+			respondRecurringError(w, err)
+			return
+		}
+	}
+
+	if err := s.UpdateRecurringTransaction(ctx, &rt); err != nil {
+		respondRecurringError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, rt)
+}
+
+// HandlePauseRecurringTransaction menangani POST /api/recurring/{id}/pause
+func (s *Store) HandlePauseRecurringTransaction(w http.ResponseWriter, r *http.Request) {
+	s.handleRecurringAction(w, r, s.PauseRecurringTransaction)
+}
+
+// HandleResumeRecurringTransaction menangani POST /api/recurring/{id}/resume
+func (s *Store) HandleResumeRecurringTransaction(w http.ResponseWriter, r *http.Request) {
+	s.handleRecurringAction(w, r, s.ResumeRecurringTransaction)
+}
+
+// HandleSkipRecurringOccurrence menangani POST /api/recurring/{id}/skip
+func (s *Store) HandleSkipRecurringOccurrence(w http.ResponseWriter, r *http.Request) {
+	s.handleRecurringAction(w, r, s.SkipRecurringOccurrence)
+}
+
+// handleRecurringAction menjalankan aksi pada satu jadwal dan mengembalikan jadwal terbarunya
+func (s *Store) handleRecurringAction(w http.ResponseWriter, r *http.Request,
+	action func(context.Context, int64) (*models.RecurringTransaction, error)) {
+	id, err := parseIDFromVars(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rt, err := action(r.Context(), id)
+	if err != nil {
+		respondRecurringError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, rt)
+}
+
+// respondRecurringError memetakan error jadwal berulang ke status HTTP
+func respondRecurringError(w http.ResponseWriter, err error) {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, ErrRecurringNotFound), errors.Is(err, pgx.ErrNoRows):
+		respondError(w, http.StatusNotFound, ErrRecurringNotFound.Error())
+	case errors.Is(err, ErrNoOccurrences):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrRecurringFinished):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.As(err, &pgErr) && pgErr.Code == "23503":
+		// 23503 = foreign key violation: akun sumber/tujuan tidak ada
+		respondError(w, http.StatusBadRequest, "One of the accounts does not exist.")
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// ErrRecurringRunInProgress dikembalikan jika instance lain sedang menjalankan worker
+var ErrRecurringRunInProgress = errors.New("recurring transactions are already being processed")
+
+// recurringLockKey adalah kunci advisory lock Postgres untuk worker transaksi berulang.
+// Setiap instance server menjalankan cron, tapi hanya satu yang memproses dalam satu waktu.
+const recurringLockKey int64 = 0x72656375 // "recu"
+
+// recurringRunTimeout membatasi lama run yang dipicu manual. Run tidak ikut berhenti jika
+// client memutus request, agar transaksi yang sedang diposting tidak terpotong.
+const recurringRunTimeout = 10 * time.Minute
+
+// Pemicu run worker
+const (
+	RecurringTriggerCron   = "cron"
+	RecurringTriggerManual = "manual"
+)
+
+// RunRecurringTransactions menjalankan ProcessRecurringTransactions di bawah advisory lock
+// dan mencatatnya di tabel recurring_runs. Jika lock sedang dipegang instance lain,
+// run dilewati dengan ErrRecurringRunInProgress.
+func (s *Store) RunRecurringTransactions(ctx context.Context, trigger string) (*models.RecurringRun, error) {
+	// Advisory lock milik session, jadi harus dipegang di koneksi yang sama sampai selesai
+	conn, err := s.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, recurringLockKey).Scan(&locked); err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, ErrRecurringRunInProgress
+	}
+	defer func() {
+		// Pakai context baru agar lock tetap dilepas walau ctx request sudah dibatalkan
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, recurringLockKey); err != nil {
+			conn.Conn().Close(context.Background()) // Lock ikut lepas saat koneksi ditutup
+		}
+	}()
+
+	// Lock sudah dipegang, jadi run yang masih 'running' berasal dari proses yang berhenti
+	// di tengah jalan (misal server mati) dan tidak akan pernah selesai
+	_, err = s.Pool.Exec(ctx, `
+		UPDATE recurring_runs
+		SET status = 'failed', finished_at = NOW(), error = 'run was interrupted before it finished'
+		WHERE status = 'running'`)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &models.RecurringRun{Trigger: trigger, Status: "running", Errors: make([]models.RecurringRunError, 0)}
+	err = s.Pool.QueryRow(ctx, `INSERT INTO recurring_runs (trigger) VALUES ($1) RETURNING id, started_at`, trigger).
+		Scan(&run.ID, &run.StartedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	result, runErr := s.ProcessRecurringTransactions(ctx)
+	if runErr != nil {
+		run.Status = "failed"
+		msg := runErr.Error()
+		run.Error = &msg
+	} else {
+		run.Status = "completed"
+		run.Schedules, run.Posted, run.Failed, run.Errors = result.Schedules, result.Posted, result.Failed, result.Errors
+	}
+
+	errorsJSON, err := json.Marshal(run.Errors)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Pool.QueryRow(context.WithoutCancel(ctx), `
+		UPDATE recurring_runs
+		SET status = $1, finished_at = NOW(), schedules = $2, posted = $3, failed = $4, errors = $5, error = $6
+		WHERE id = $7
+		RETURNING finished_at`,
+		run.Status, run.Schedules, run.Posted, run.Failed, string(errorsJSON), run.Error, run.ID,
+	).Scan(&run.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	return run, runErr
+}
+
+// GetRecurringRuns mengambil log run worker terbaru lebih dulu
+func (s *Store) GetRecurringRuns(ctx context.Context, limit int) ([]models.RecurringRun, error) {
+	rows, err := s.Pool.Query(ctx, `
+		SELECT id, trigger, status, started_at, finished_at, schedules, posted, failed, errors, error
+		FROM recurring_runs
+		ORDER BY started_at DESC, id DESC
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make([]models.RecurringRun, 0)
+	for rows.Next() {
+		var run models.RecurringRun
+		var errorsJSON []byte
+		if err := rows.Scan(&run.ID, &run.Trigger, &run.Status, &run.StartedAt, &run.FinishedAt,
+			&run.Schedules, &run.Posted, &run.Failed, &errorsJSON, &run.Error); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(errorsJSON, &run.Errors); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// HandleRunRecurring menangani POST /api/admin/recurring/run (khusus admin)
+func (s *Store) HandleRunRecurring(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), recurringRunTimeout)
+	defer cancel()
+
+	run, err := s.RunRecurringTransactions(ctx, RecurringTriggerManual)
+	switch {
+	case errors.Is(err, ErrRecurringRunInProgress):
+		respondError(w, http.StatusConflict, err.Error())
+	case run != nil:
+		// Run yang gagal tetap tercatat; kembalikan log-nya beserta pesan error
+		respondJSON(w, http.StatusOK, run)
+	case err != nil:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// HandleGetRecurringRuns menangani GET /api/admin/recurring/run?limit=20 (khusus admin)
+func (s *Store) HandleGetRecurringRuns(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 || limit > 200 {
+		limit = 20
+	}
+
+	runs, err := s.GetRecurringRuns(r.Context(), limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, runs)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/exporter"
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// HandleStatementPDF membuat laporan rekening bulanan dalam format PDF.
+// Query param: month, year (default bulan ini) dan account_id (kosong = semua akun).
+func (s *Store) HandleStatementPDF(w http.ResponseWriter, r *http.Request) {
+	month, year := parseMonthYear(r)
+	accountID := parseAccountID(r)
+	ctx := r.Context()
+
+	cal := calendarFrom(ctx)
+	now := time.Now().In(cal.loc)
+	startDate, endDate := cal.monthRange(month, year)
+
+	st := exporter.Statement{Month: month, Year: year, StartDate: startDate, EndDate: endDate, AccountID: accountID, GeneratedAt: now}
+
+	// 1. Saldo awal & akhir periode
+	balances, err := s.GetAccountBalancesForPeriod(ctx, startDate, endDate, accountID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch account balances")
+		return
+	}
+	if accountID > 0 {
+		if len(balances) == 0 {
+			respondError(w, http.StatusNotFound, "Account not found")
+			return
+		}
+		st.AccountName = balances[0].Name
+	}
+	for _, b := range balances {
+		st.OpeningBalance += b.OpeningBalance
+		st.ClosingBalance += b.ClosingBalance
+	}
+
+	// 2. Data yang sama dengan /summary dan /summary/categories
+	st.Summary, err = s.GetSummary(ctx, startDate, endDate, accountID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch summary")
+		return
+	}
+	st.Categories, err = s.GetCategorySummary(ctx, startDate, endDate, accountID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch category summary")
+		return
+	}
+
+	// 3. Transaksi dan budget bulan ini
+	st.Transactions, err = s.GetTransactionsForExport(ctx, models.TransactionFilter{StartDate: startDate, EndDate: endDate, AccountID: accountID})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch transactions")
+		return
+	}
+	st.Budgets, err = s.GetBudgetVsActual(ctx, startDate, endDate, accountID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch budgets")
+		return
+	}
+
+	// 4. PDF dibuat di memori dulu agar error masih bisa dikirim sebagai JSON
+	var buf bytes.Buffer
+	if err := exporter.WriteStatementPDF(&buf, st); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to generate PDF")
+		return
+	}
+
+	filename := fmt.Sprintf("laporan-rekening-%d-%02d.pdf", year, month)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	if _, err := buf.WriteTo(w); err != nil {
+		log.Printf("Failed to write %s: %v", filename, err)
+	}
+}
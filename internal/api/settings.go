@@ -0,0 +1,272 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrNoToken      = errors.New("missing bearer token")
+	ErrUserNotFound = errors.New("user not found")
+)
+
+type settingsKey struct{}
+
+// settingsCacheTTL membatasi umur cache pengaturan aplikasi. Setiap instance server punya
+// cache sendiri, jadi perubahan dari instance lain terlihat paling lambat setelah waktu ini.
+const settingsCacheTTL = 30 * time.Second
+
+func defaultSettings() models.AppSettings {
+	return models.AppSettings{MonthStartDay: 1, Timezone: models.DefaultTimezone}
+}
+
+// settingsFrom mengambil pengaturan aplikasi dari context, atau pengaturan default jika
+// context belum melewati SettingsMiddleware/WithAppSettings
+func settingsFrom(ctx context.Context) models.AppSettings {
+	if settings, ok := ctx.Value(settingsKey{}).(models.AppSettings); ok {
+		return settings
+	}
+	return defaultSettings()
+}
+
+func withSettings(ctx context.Context, settings models.AppSettings) context.Context {
+	return context.WithValue(ctx, settingsKey{}, settings)
+}
+
+// GetAppSettings mengembalikan pengaturan aplikasi. Pengaturan ini berlaku untuk semua
+// pengguna karena data keuangannya dipakai bersama. Hasilnya di-cache selama settingsCacheTTL.
+func (s *Store) GetAppSettings(ctx context.Context) (models.AppSettings, error) {
+	s.settingsMu.Lock()
+	cached, loadedAt := s.settings, s.settingsAt
+	s.settingsMu.Unlock()
+	if cached != nil && time.Since(loadedAt) < settingsCacheTTL {
+		return *cached, nil
+	}
+
+	settings := defaultSettings()
+	err := s.Pool.QueryRow(ctx, `SELECT month_start_day, timezone FROM app_settings`).
+		Scan(&settings.MonthStartDay, &settings.Timezone)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return defaultSettings(), err
+	}
+	s.cacheSettings(settings)
+	return settings, nil
+}
+
+// UpdateAppSettings menyimpan pengaturan aplikasi dan memperbarui cache instance ini
+func (s *Store) UpdateAppSettings(ctx context.Context, settings models.AppSettings) error {
+	_, err := s.Pool.Exec(ctx, `
+		INSERT INTO app_settings (id, month_start_day, timezone) VALUES (TRUE, $1, $2)
+		ON CONFLICT (id) DO UPDATE SET month_start_day = EXCLUDED.month_start_day,
+			timezone = EXCLUDED.timezone, updated_at = NOW()`,
+		settings.MonthStartDay, settings.Timezone)
+	if err != nil {
+		return err
+	}
+	s.cacheSettings(settings)
+	return nil
+}
+
+func (s *Store) cacheSettings(settings models.AppSettings) {
+	s.settingsMu.Lock()
+	s.settings, s.settingsAt = &settings, time.Now()
+	s.settingsMu.Unlock()
+}
+
+// WithAppSettings memasang pengaturan aplikasi ke context, jika belum ada. Dipakai oleh
+// middleware dan pekerjaan di background (misal cron job) yang tidak punya request.
+func (s *Store) WithAppSettings(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(settingsKey{}).(models.AppSettings); ok {
+		return ctx
+	}
+
+	settings, err := s.GetAppSettings(ctx)
+	if err != nil {
+		log.Printf("Failed to load app settings, using defaults: %v", err)
+	}
+	return withSettings(ctx, settings)
+}
+
+// validTimezone memeriksa nama zona waktu; kosong berarti zona waktu default
+func validTimezone(settings *models.AppSettings) error {
+	if settings.Timezone == "" {
+		settings.Timezone = models.DefaultTimezone
+	}
+	if _, err := time.LoadLocation(settings.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q, use an IANA name such as Asia/Jakarta", settings.Timezone)
+	}
+	return nil
+}
+
+// userIDFromRequest membaca ID pengguna dari header "Authorization: Bearer <jwt>"
+func (s *Store) userIDFromRequest(r *http.Request) (int64, error) {
+	tokenString, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || tokenString == "" {
+		return 0, ErrNoToken
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(s.jwtSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return 0, err
+	}
+
+	sub, ok := claims["sub"].(float64) // Angka di JSON selalu float64
+	if !ok {
+		return 0, errors.New("invalid token subject")
+	}
+	return int64(sub), nil
+}
+
+// requireAdmin memastikan request berasal dari pengguna admin. Jika tidak, respons 401/403
+// sudah dikirim dan handler harus berhenti.
+func (s *Store) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	userID, err := s.userIDFromRequest(r)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Invalid or missing token")
+		return false
+	}
+
+	var isAdmin bool
+	err = s.Pool.QueryRow(r.Context(), `SELECT is_admin FROM users WHERE id = $1`, userID).Scan(&isAdmin)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	if !isAdmin {
+		respondError(w, http.StatusForbidden, "Admin access required")
+		return false
+	}
+	return true
+}
+
+// SettingsMiddleware memuat pengaturan aplikasi ke context request, sehingga periode
+// default di semua handler mengikuti pengaturan tersebut
+func (s *Store) SettingsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(s.WithAppSettings(r.Context())))
+	})
+}
+
+// financialPeriod menyusun batas bulan keuangan (month, year)
+func (c calendar) financialPeriod(month, year int) models.FinancialPeriod {
+	start, end := c.monthRange(month, year)
+	totalDays, _ := elapsedDays(start, end, start)
+	return models.FinancialPeriod{
+		Month:         int(start.Month()),
+		Year:          start.Year(),
+		MonthStartDay: c.monthStartDay,
+		Start:         start,
+		End:           end,
+		Days:          totalDays,
+	}
+}
+
+// HandleGetSettings menangani GET /api/settings (butuh token)
+func (s *Store) HandleGetSettings(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.userIDFromRequest(r); err != nil {
+		respondError(w, http.StatusUnauthorized, "Invalid or missing token")
+		return
+	}
+
+	settings, err := s.GetAppSettings(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, settings)
+}
+
+// HandleUpdateSettings menangani PUT /api/settings (khusus admin). Pengaturan berlaku untuk
+// semua pengguna; field yang tidak dikirim tidak diubah.
+func (s *Store) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	settings, err := s.GetAppSettings(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Field yang tidak dikirim tetap memakai nilai sekarang
+	var req struct {
+		MonthStartDay *int    `json:"month_start_day"`
+		Timezone      *string `json:"timezone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if req.MonthStartDay != nil {
+		settings.MonthStartDay = *req.MonthStartDay
+	}
+	if req.Timezone != nil {
+		settings.Timezone = *req.Timezone
+	}
+	if settings.MonthStartDay < 1 || settings.MonthStartDay > 31 {
+		respondError(w, http.StatusBadRequest, "month_start_day must be 1-31")
+		return
+	}
+	if err := validTimezone(&settings); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.UpdateAppSettings(r.Context(), settings); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, settings)
+}
+
+// HandleGetCurrentPeriod menangani GET /api/periods/current?date=yyyy-mm-dd
+// dan mengembalikan bulan keuangan yang memuat tanggal tersebut (default hari ini)
+func (s *Store) HandleGetCurrentPeriod(w http.ResponseWriter, r *http.Request) {
+	cal := calendarFrom(r.Context())
+
+	date := time.Now()
+	if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateStr, cal.loc)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid date format, use yyyy-mm-dd")
+			return
+		}
+		date = parsed
+	}
+
+	respondJSON(w, http.StatusOK, cal.financialPeriod(cal.monthOf(date)))
+}
+
+// HandleGetPeriods menangani GET /api/periods?year=
+// dan mengembalikan 12 bulan keuangan yang dimulai di tahun tersebut
+func (s *Store) HandleGetPeriods(w http.ResponseWriter, r *http.Request) {
+	cal := calendarFrom(r.Context())
+
+	year, err := strconv.Atoi(r.URL.Query().Get("year"))
+	if err != nil || year < 1 {
+		_, year = cal.monthOf(time.Now())
+	}
+
+	periods := make([]models.FinancialPeriod, 0, 12)
+	for month := 1; month <= 12; month++ {
+		periods = append(periods, cal.financialPeriod(month, year))
+	}
+	respondJSON(w, http.StatusOK, periods)
+}
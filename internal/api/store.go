@@ -5,15 +5,28 @@ import (
 	"errors"
 	"fmt"
 	"github.com/bramszs/finance-tracker/internal/models"
+	"github.com/bramszs/finance-tracker/internal/notify"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
 	"math"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
 type Store struct {
 	Pool      *pgxpool.Pool
 	jwtSecret string
+
+	// Notifier meneruskan notifikasi ke email/webhook; nil berarti hanya in-app
+	Notifier *notify.Dispatcher
+
+	// Cache pengaturan aplikasi (tabel app_settings), nil jika belum dimuat
+	settingsMu sync.Mutex
+	settings   *models.AppSettings
+	settingsAt time.Time
 }
 
 func NewStore(pool *pgxpool.Pool, jwtSecret string) *Store {
@@ -70,13 +83,15 @@ func applyTransactionBalance(ctx context.Context, tx pgx.Tx, newTx models.Transa
 	return nil
 }
 func (s *Store) CreateUser(ctx context.Context, user *models.User) error {
+	// Pengguna pertama yang mendaftar menjadi admin
 	query := `
-		INSERT INTO users (name, email, password_hash) 
-		VALUES ($1, $2, $3) 
-		RETURNING id, created_at`
+		INSERT INTO users (name, email, password_hash, is_admin) 
+		VALUES ($1, $2, $3, NOT EXISTS (SELECT 1 FROM users)) 
+		RETURNING id, is_admin, created_at`
 
 	err := s.Pool.QueryRow(ctx, query, user.Name, user.Email, user.PasswordHash).Scan(
 		&user.ID,
+		&user.IsAdmin,
 		&user.CreatedAt,
 	)
 
@@ -100,6 +115,10 @@ func (s *Store) GetUserByEmail(ctx context.Context, email string) (*models.User,
 
 	return &user, nil
 }
+
+// ErrAccountNotFound dikembalikan jika saldo akun yang dituju tidak bisa di-update
+var ErrAccountNotFound = errors.New("account not found, balance not updated")
+
 func updateAccountBalance(ctx context.Context, tx pgx.Tx, accountID int64, amountChange int64) error {
 	query := `
 		UPDATE accounts 
@@ -111,7 +130,7 @@ func updateAccountBalance(ctx context.Context, tx pgx.Tx, accountID int64, amoun
 		return err
 	}
 	if ct.RowsAffected() == 0 {
-		return errors.New("account not found, balance not updated")
+		return ErrAccountNotFound
 	}
 	return nil
 }
@@ -208,55 +227,89 @@ func (s *Store) CreateTransaction(ctx context.Context, txData *models.Transactio
 	// Pastikan di-rollback jika ada error
 	defer tx.Rollback(ctx)
 
-	// 2. Masukkan Transaksi
-	query := `
-		INSERT INTO transactions (amount, type, category, description, date, account_id)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at`
+	// 2. Masukkan transaksi & update saldo akun
+	if err := insertTransaction(ctx, tx, txData); err != nil {
+		return err // Rollback akan otomatis dipanggil
+	}
 
+	// 3. Commit Transaksi
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	// 4. Cek ambang budget kategori ini
+	s.checkBudgetAlerts(ctx, *txData)
+	return nil
+}
+
+// insertTransaction menyimpan satu transaksi (income, expense atau transfer) di dalam tx
+// dan langsung menyesuaikan saldo akun yang terlibat. Dipakai oleh CreateTransaction,
+// CreateTransfer dan worker transaksi berulang.
+func insertTransaction(ctx context.Context, tx pgx.Tx, txData *models.Transaction) error {
 	// Pastikan amount SELALU positif
 	if txData.Amount < 0 {
 		txData.Amount = -txData.Amount
 	}
 
-	err = tx.QueryRow(ctx, query,
+	// 1. Tentukan Perubahan Saldo
+	var balanceChange int64
+	switch txData.Type {
+	case "income":
+		balanceChange = txData.Amount
+		txData.DestinationAccountID = nil
+	case "expense":
+		balanceChange = -txData.Amount
+		txData.DestinationAccountID = nil
+	case "transfer":
+		if txData.DestinationAccountID == nil || *txData.DestinationAccountID == 0 {
+			return errors.New("invalid data for transfer")
+		}
+		balanceChange = -txData.Amount
+		txData.Category = "Transfer" // Set kategori default
+	default:
+		return errors.New("invalid transaction type for create")
+	}
+
+	// 2. Masukkan Transaksi
+	query := `
+		INSERT INTO transactions (amount, type, category, description, date, account_id, destination_account_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+
+	err := tx.QueryRow(ctx, query,
 		txData.Amount,
 		txData.Type,
 		txData.Category,
 		txData.Description,
 		txData.Date,
 		txData.AccountID,
+		txData.DestinationAccountID,
 	).Scan(&txData.ID, &txData.CreatedAt)
-
 	if err != nil {
 		return err
 	}
 
-	// 3. Tentukan Perubahan Saldo
-	var balanceChange int64
-	if txData.Type == "income" {
-		balanceChange = txData.Amount
-	} else if txData.Type == "expense" {
-		balanceChange = -txData.Amount
-	} else {
-		return errors.New("invalid transaction type for create")
-	}
-
-	// 4. Update Saldo Akun (menggunakan helper)
+	// 3. Update Saldo Akun Asal (menggunakan helper)
 	if err := updateAccountBalance(ctx, tx, txData.AccountID, balanceChange); err != nil {
-		return err // Rollback akan otomatis dipanggil
+		return err
 	}
 
-	// 5. Commit Transaksi
-	return tx.Commit(ctx)
+	// 4. Transfer: tambah saldo akun tujuan
+	if txData.Type == "transfer" {
+		if err := updateAccountBalance(ctx, tx, *txData.DestinationAccountID, txData.Amount); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (s *Store) GetTransactions(ctx context.Context, page int, limit int) (*models.PaginatedTransactionsResponse, error) {
+func (s *Store) GetTransactions(ctx context.Context, page int, limit int, filter models.TransactionFilter) (*models.PaginatedTransactionsResponse, error) {
+	where, args := transactionFilterClause(filter, nil)
 
 	// --- Langkah 1: Dapatkan Total Item (COUNT) ---
 	var totalItems int64
-	countQuery := "SELECT COUNT(*) FROM transactions"
-	err := s.Pool.QueryRow(ctx, countQuery).Scan(&totalItems)
+	countQuery := "SELECT COUNT(*) FROM transactions t WHERE 1=1" + where
+	err := s.Pool.QueryRow(ctx, countQuery, args...).Scan(&totalItems)
 	if err != nil {
 		return nil, err
 	}
@@ -273,19 +326,21 @@ func (s *Store) GetTransactions(ctx context.Context, page int, limit int) (*mode
 	offset := (page - 1) * limit
 
 	// --- Langkah 3: Ambil Data Halaman Ini (LIMIT/OFFSET) ---
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, amount, type, category, description, date, 
 		       created_at, account_id, destination_account_id 
-		FROM transactions 
+		FROM transactions t
+		WHERE 1=1%s
 		ORDER BY date DESC
-		LIMIT $1 OFFSET $2`
+		LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
 
-	rows, err := s.Pool.Query(ctx, query, limit, offset)
+	rows, err := s.Pool.Query(ctx, query, append(args, limit, offset)...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	loc := calendarFrom(ctx).loc
 	transactions := make([]models.Transaction, 0)
 	for rows.Next() {
 		var tx models.Transaction
@@ -296,6 +351,7 @@ func (s *Store) GetTransactions(ctx context.Context, page int, limit int) (*mode
 		if err != nil {
 			return nil, err
 		}
+		tx.Date = tx.Date.In(loc)
 		transactions = append(transactions, tx)
 	}
 	if err := rows.Err(); err != nil {
@@ -424,6 +480,7 @@ func (s *Store) GetTransactionByID(ctx context.Context, id int64) (models.Transa
 		&tx.Date,
 		&tx.CreatedAt,
 	)
+	tx.Date = tx.Date.In(calendarFrom(ctx).loc)
 
 	return tx, err // Jika tidak ada, 'err' akan otomatis 'no rows in result set'
 }
@@ -481,94 +538,243 @@ func (s *Store) UpdateTransaction(ctx context.Context, newTxData *models.Transac
 	}
 
 	// 6. Commit
-	return tx.Commit(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	// 7. Cek ambang budget kategori (baru) transaksi ini
+	s.checkBudgetAlerts(ctx, *newTxData)
+	return nil
 }
 
 func (s *Store) SetBudget(ctx context.Context, budget *models.Budget) error {
+	if budget.Rollover == "" {
+		budget.Rollover = models.RolloverNone
+	}
+	if budget.PeriodType == "" {
+		budget.PeriodType = models.PeriodMonthly
+	}
+
 	query := `
-		INSERT INTO budgets (category_name, month, year, amount)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO budgets (category_name, month, year, amount, rollover, rollover_cap, period_type, period_start_day)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (category_name, month, year)
-		DO UPDATE SET amount = $4
+		DO UPDATE SET amount = $4, rollover = $5, rollover_cap = $6, period_type = $7, period_start_day = $8
 		RETURNING id, created_at
 	`
 
-	err := s.Pool.QueryRow(ctx, query,
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, query,
 		budget.CategoryName,
 		budget.Month,
 		budget.Year,
 		budget.Amount,
+		budget.Rollover,
+		budget.RolloverCap,
+		budget.PeriodType,
+		budget.PeriodStartDay,
 	).Scan(&budget.ID, &budget.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := checkBudgetOverlap(ctx, tx, budget.CategoryName, budget.Month, budget.Year); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
 
-	return err
+	budgets := []models.Budget{*budget}
+	if err := s.applyRollover(ctx, budgets); err != nil {
+		return err
+	}
+	calendarFrom(ctx).setBudgetWindow(&budgets[0], time.Now())
+	*budget = budgets[0]
+	return nil
 }
 
-// GetBudgets mengambil semua data budget untuk bulan & tahun tertentu
-func (s *Store) GetBudgets(ctx context.Context, month int, year int) ([]models.Budget, error) {
-	query := `
-		SELECT id, category_name, amount, month, year, created_at
-		FROM budgets
-		WHERE month = $1 AND year = $2
-	`
+// budgetColumns adalah kolom yang dibaca scanBudgets
+const budgetColumns = `id, category_name, amount, month, year, rollover, rollover_cap, period_type, period_start_day, created_at`
 
-	rows, err := s.Pool.Query(ctx, query, month, year)
-	if err != nil {
-		return nil, err
-	}
+func scanBudgets(rows pgx.Rows) ([]models.Budget, error) {
 	defer rows.Close()
 
 	budgets := make([]models.Budget, 0)
 	for rows.Next() {
 		var b models.Budget
-		if err := rows.Scan(&b.ID, &b.CategoryName, &b.Amount, &b.Month, &b.Year, &b.CreatedAt); err != nil {
+		if err := rows.Scan(&b.ID, &b.CategoryName, &b.Amount, &b.Month, &b.Year, &b.Rollover, &b.RolloverCap,
+			&b.PeriodType, &b.PeriodStartDay, &b.CreatedAt); err != nil {
 			return nil, err
 		}
 		budgets = append(budgets, b)
 	}
+	return budgets, rows.Err()
+}
 
+// dropShadowedYearly membuang budget tahunan dari bulan sebelumnya jika kategori yang sama
+// punya budget sendiri di bulan (month, year). Penulisan baru sudah ditolak checkBudgetOverlap;
+// ini untuk data lama, dan budget yang dicatat untuk bulan itu yang menang.
+func dropShadowedYearly(budgets []models.Budget, month, year int) []models.Budget {
+	own := make(map[string]bool)
+	for _, b := range budgets {
+		if b.Month == month && b.Year == year {
+			own[categoryKey(b.CategoryName)] = true
+		}
+	}
+	kept := budgets[:0]
+	for _, b := range budgets {
+		if (b.Month != month || b.Year != year) && own[categoryKey(b.CategoryName)] {
+			continue
+		}
+		kept = append(kept, b)
+	}
+	return kept
+}
+
+// GetBudgets mengambil semua data budget untuk bulan & tahun tertentu, termasuk budget
+// tahunan yang periodenya mencakup bulan itu, lengkap dengan sisa yang dibawa dari bulan
+// sebelumnya (rollover) dan rentang tanggal periodenya
+func (s *Store) GetBudgets(ctx context.Context, month int, year int) ([]models.Budget, error) {
+	query := `
+		SELECT ` + budgetColumns + `
+		FROM budgets
+		WHERE (month = $1 AND year = $2)
+			OR (period_type = 'yearly' AND (year * 12 + month - 1) BETWEEN $3 - 11 AND $3)
+		ORDER BY category_name, year, month
+	`
+
+	rows, err := s.Pool.Query(ctx, query, month, year, monthIndex(month, year))
+	if err != nil {
+		return nil, err
+	}
+	budgets, err := scanBudgets(rows)
+	if err != nil {
+		return nil, err
+	}
+	budgets = dropShadowedYearly(budgets, month, year)
+
+	if err := s.applyRollover(ctx, budgets); err != nil {
+		return nil, err
+	}
+	cal, now := calendarFrom(ctx), time.Now()
+	for i := range budgets {
+		cal.setBudgetWindow(&budgets[i], now)
+	}
 	return budgets, nil
 }
 
 func (s *Store) CreateRecurringTransaction(ctx context.Context, rt *models.RecurringTransaction) error {
 	query := `
 		INSERT INTO recurring_transactions 
-			(amount, type, category, description, frequency, "interval", start_date, next_due_date)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			(amount, type, category, description, account_id, destination_account_id,
+			 frequency, "interval", rrule, business_day_shift, start_date, next_due_date, end_date, max_occurrences)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11, $12, $13, $14)
 		RETURNING id, created_at`
 
-	// 'next_due_date' saat pertama kali dibuat adalah sama dengan 'start_date'
+	// Tanpa next_due_date (dihitung handler dari aturan), kejadian pertama adalah 'start_date'
+	if rt.NextDueDate.IsZero() {
+		rt.NextDueDate = rt.StartDate
+	}
 	err := s.Pool.QueryRow(ctx, query,
-		rt.Amount, rt.Type, rt.Category, rt.Description,
-		rt.Frequency, rt.Interval, rt.StartDate, rt.StartDate,
+		rt.Amount, rt.Type, rt.Category, rt.Description, rt.AccountID, rt.DestinationAccountID,
+		rt.Frequency, rt.Interval, rt.RRule, rt.BusinessDayShift, rt.StartDate, rt.NextDueDate,
+		rt.EndDate, rt.MaxOccurrences,
 	).Scan(&rt.ID, &rt.CreatedAt)
-	rt.NextDueDate = rt.StartDate // Pastikan struct-nya update
+	setRecurringStatus(rt)
 	return err
 }
 
-// GetRecurringTransactions mengambil semua jadwal
-func (s *Store) GetRecurringTransactions(ctx context.Context) ([]models.RecurringTransaction, error) {
-	query := `SELECT * FROM recurring_transactions ORDER BY next_due_date ASC`
+// validateRecurring memeriksa jadwal berulang sebelum disimpan. Sama seperti transaksi biasa,
+// amount dinormalisasi menjadi positif dan hanya transfer yang punya akun tujuan.
+func validateRecurring(rt *models.RecurringTransaction) error {
+	if rt.Amount < 0 {
+		rt.Amount = -rt.Amount
+	}
+	if rt.Amount == 0 {
+		return errors.New("amount cannot be zero")
+	}
+	if rt.AccountID <= 0 {
+		return errors.New("invalid account ID")
+	}
 
-	rows, err := s.Pool.Query(ctx, query)
-	if err != nil {
-		return nil, err
+	switch rt.Type {
+	case "income", "expense":
+		if rt.Category == "" {
+			return errors.New("category is required")
+		}
+		rt.DestinationAccountID = nil
+	case "transfer":
+		if rt.DestinationAccountID == nil || *rt.DestinationAccountID <= 0 {
+			return errors.New("invalid destination account ID")
+		}
+		if rt.AccountID == *rt.DestinationAccountID {
+			return errors.New("source and destination accounts cannot be the same")
+		}
+		rt.Category = "Transfer"
+	default:
+		return errors.New("type must be 'income', 'expense' or 'transfer'")
 	}
+
+	if len(rt.Category) > 50 {
+		return errors.New("category is longer than 50 characters")
+	}
+	if err := normalizeRecurrenceRule(rt); err != nil {
+		return err
+	}
+	if !recurringFrequencies[rt.Frequency] || rt.Interval < 1 {
+		return errors.New("frequency must be daily, weekly, monthly or yearly with an interval of at least 1")
+	}
+	if rt.MaxOccurrences != nil && *rt.MaxOccurrences < 1 {
+		return errors.New("max_occurrences must be at least 1")
+	}
+	if rt.EndDate != nil && !rt.StartDate.IsZero() && rt.EndDate.Before(rt.StartDate) {
+		return errors.New("end_date cannot be before start_date")
+	}
+	return nil
+}
+
+// recurringColumns adalah urutan kolom yang dibaca oleh scanRecurring
+const recurringColumns = `id, amount, type, category, description, account_id, destination_account_id,
+	frequency, "interval", COALESCE(rrule, ''), business_day_shift, start_date, next_due_date,
+	end_date, max_occurrences, occurrences, paused_at,
+	last_error, last_error_at, created_at`
+
+// scanRecurring membaca semua baris hasil query yang memilih recurringColumns
+func scanRecurring(rows pgx.Rows) ([]models.RecurringTransaction, error) {
 	defer rows.Close()
 
 	transactions := make([]models.RecurringTransaction, 0)
 	for rows.Next() {
 		var rt models.RecurringTransaction
-		// Hati-hati: urutan scan harus sama dengan kolom tabel
 		err := rows.Scan(
-			&rt.ID, &rt.Amount, &rt.Type, &rt.Category, &rt.Description,
-			&rt.Frequency, &rt.Interval, &rt.StartDate, &rt.NextDueDate, &rt.CreatedAt,
+			&rt.ID, &rt.Amount, &rt.Type, &rt.Category, &rt.Description, &rt.AccountID, &rt.DestinationAccountID,
+			&rt.Frequency, &rt.Interval, &rt.RRule, &rt.BusinessDayShift, &rt.StartDate, &rt.NextDueDate,
+			&rt.EndDate, &rt.MaxOccurrences, &rt.Occurrences, &rt.PausedAt,
+			&rt.LastError, &rt.LastErrorAt, &rt.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		setRecurringStatus(&rt)
 		transactions = append(transactions, rt)
 	}
-	return transactions, nil
+	return transactions, rows.Err()
+}
+
+// GetRecurringTransactions mengambil semua jadwal
+func (s *Store) GetRecurringTransactions(ctx context.Context) ([]models.RecurringTransaction, error) {
+	query := `SELECT ` + recurringColumns + ` FROM recurring_transactions ORDER BY next_due_date ASC`
+
+	rows, err := s.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return scanRecurring(rows)
 }
 
 // DeleteRecurringTransaction menghapus jadwal
@@ -579,21 +785,56 @@ func (s *Store) DeleteRecurringTransaction(ctx context.Context, id int64) error
 
 // === FUNGSI WORKER (INTI FITUR) ===
 
-// ProcessRecurringTransactions adalah fungsi yang akan dijalankan oleh Cron Job
-func (s *Store) ProcessRecurringTransactions(ctx context.Context) (int, error) {
-	// 1. Ambil semua jadwal yang sudah jatuh tempo (kemarin, hari ini)
-	query := `SELECT * FROM recurring_transactions WHERE next_due_date <= NOW()`
+// maxRecurringCatchUp membatasi jumlah kejadian yang diposting per jadwal dalam satu run.
+// Sisanya diposting pada run berikutnya.
+const maxRecurringCatchUp = 1000
 
-	rows, err := s.Pool.Query(ctx, query)
+// ProcessRecurringTransactions adalah fungsi yang akan dijalankan oleh Cron Job.
+// Setiap jadwal diproses dalam transaksinya sendiri, jadi satu jadwal yang gagal
+// tidak membatalkan jadwal lain; error-nya dicatat di jadwal tersebut.
+func (s *Store) ProcessRecurringTransactions(ctx context.Context) (*models.RecurringRunResult, error) {
+	// Worker tidak punya request, jadi pengaturan aplikasi dimuat di sini
+	ctx = s.WithAppSettings(ctx)
+	today := dateKey(calendarFrom(ctx).today())
+
+	// 1. Ambil semua jadwal aktif yang sudah jatuh tempo (kemarin, hari ini, atau terlewat)
+	query := `SELECT ` + recurringColumns + ` FROM recurring_transactions WHERE next_due_date <= $1 AND ` + recurringActiveClause +
+		` ORDER BY next_due_date, id`
+
+	rows, err := s.Pool.Query(ctx, query, today)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	due, err := scanRecurring(rows)
+	if err != nil {
+		return nil, err
 	}
-	defer rows.Close()
 
-	processedCount := 0
+	holidays, err := s.loadHolidays(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Proses satu per satu
+	result := &models.RecurringRunResult{Schedules: len(due), Errors: make([]models.RecurringRunError, 0)}
+	for _, rt := range due {
+		posted, err := s.postRecurring(ctx, rt, today, holidays)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, models.RecurringRunError{RecurringID: rt.ID, Error: err.Error()})
+			s.recordRecurringError(ctx, rt.ID, err)
+			continue
+		}
+		result.Posted += posted
+	}
+	return result, nil
+}
+
+// postRecurring memposting semua kejadian jadwal rt yang jatuh tempo sampai hari ini
+// (kejadian yang terlewat ikut diposting) dalam satu transaksi database.
+func (s *Store) postRecurring(ctx context.Context, rt models.RecurringTransaction, today string, holidays holidaySet) (int, error) {
+	cal := calendarFrom(ctx)
 
-	// 2. Mulai transaksi database
-	// Kita ingin semua proses ini berhasil, atau tidak sama sekali
 	tx, err := s.Pool.Begin(ctx)
 	if err != nil {
 		return 0, err
@@ -601,52 +842,75 @@ func (s *Store) ProcessRecurringTransactions(ctx context.Context) (int, error) {
 	// Pastikan di-rollback jika ada error
 	defer tx.Rollback(ctx)
 
-	for rows.Next() {
-		var rt models.RecurringTransaction
-		// ... (scan data rt) ...
-		err := rows.Scan(
-			&rt.ID, &rt.Amount, &rt.Type, &rt.Category, &rt.Description,
-			&rt.Frequency, &rt.Interval, &rt.StartDate, &rt.NextDueDate, &rt.CreatedAt,
-		)
-		if err != nil {
-			return 0, err
+	// Kunci & baca ulang jadwalnya. Jika sedang dikunci proses lain, atau sudah tidak
+	// jatuh tempo (misal baru diproses atau diubah), jadwal ini dilewati.
+	rows, err := tx.Query(ctx, `SELECT `+recurringColumns+` FROM recurring_transactions
+		WHERE id = $1 AND next_due_date <= $2 AND `+recurringActiveClause+`
+		FOR UPDATE SKIP LOCKED`, rt.ID, today)
+	if err != nil {
+		return 0, err
+	}
+	locked, err := scanRecurring(rows)
+	if err != nil {
+		return 0, err
+	}
+	if len(locked) == 0 {
+		return 0, nil
+	}
+	rt = locked[0]
+
+	posted := make([]models.Transaction, 0, 1)
+	for dateKey(rt.NextDueDate) <= today && rt.Status == models.RecurringActive && len(posted) < maxRecurringCatchUp {
+		// 3. Simpan lewat jalur yang sama dengan CreateTransaction/CreateTransfer,
+		// sehingga saldo akun ikut berubah. 'date' = tanggal jatuh tempo (tengah malam waktu pengguna)
+		txData := models.Transaction{
+			Amount:               rt.Amount,
+			Type:                 rt.Type,
+			Category:             rt.Category,
+			Description:          rt.Description,
+			Date:                 cal.atDate(rt.NextDueDate),
+			AccountID:            rt.AccountID,
+			DestinationAccountID: rt.DestinationAccountID,
 		}
-
-		// 3. MASUKKAN ke tabel 'transactions' utama
-		insertQuery := `
-			INSERT INTO transactions (amount, type, category, description, date)
-			VALUES ($1, $2, $3, $4, $5)`
-		// Kita masukkan dengan 'date' = tanggal jatuh tempo
-		_, err = tx.Exec(ctx, insertQuery, rt.Amount, rt.Type, rt.Category, rt.Description, rt.NextDueDate)
-		if err != nil {
+		if err := insertTransaction(ctx, tx, &txData); err != nil {
 			return 0, err
 		}
+		posted = append(posted, txData)
 
 		// 4. Hitung TANGGAL JATUH TEMPO BERIKUTNYA
-		var nextDueDate time.Time
-		switch rt.Frequency {
-		case "daily":
-			nextDueDate = rt.NextDueDate.AddDate(0, 0, rt.Interval)
-		case "weekly":
-			nextDueDate = rt.NextDueDate.AddDate(0, 0, 7*rt.Interval)
-		case "monthly":
-			nextDueDate = rt.NextDueDate.AddDate(0, rt.Interval, 0)
-		case "yearly":
-			nextDueDate = rt.NextDueDate.AddDate(rt.Interval, 0, 0)
-		}
-
-		// 5. UPDATE jadwal 'recurring' dengan tanggal jatuh tempo baru
-		updateQuery := `UPDATE recurring_transactions SET next_due_date = $1 WHERE id = $2`
-		_, err = tx.Exec(ctx, updateQuery, nextDueDate, rt.ID)
-		if err != nil {
+		if err := advanceRecurring(&rt, holidays); err != nil {
 			return 0, err
 		}
+	}
 
-		processedCount++
+	// 5. UPDATE jadwal 'recurring' dengan tanggal jatuh tempo baru & jumlah kejadian
+	updateQuery := `
+		UPDATE recurring_transactions
+		SET next_due_date = $1, occurrences = $2, max_occurrences = $3, last_error = NULL, last_error_at = NULL
+		WHERE id = $4`
+	if _, err := tx.Exec(ctx, updateQuery, rt.NextDueDate, rt.Occurrences, rt.MaxOccurrences, rt.ID); err != nil {
+		return 0, err
 	}
 
 	// 6. Jika semua berhasil, commit transaksi
-	return processedCount, tx.Commit(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	// 7. Cek ambang budget untuk pengeluaran yang baru diposting
+	for _, txData := range posted {
+		s.checkBudgetAlerts(ctx, txData)
+	}
+	return len(posted), nil
+}
+
+// recordRecurringError menyimpan error terakhir sebuah jadwal. Kegagalan menyimpan hanya di-log.
+func (s *Store) recordRecurringError(ctx context.Context, id int64, cause error) {
+	_, err := s.Pool.Exec(ctx, `UPDATE recurring_transactions SET last_error = $1, last_error_at = NOW() WHERE id = $2`,
+		cause.Error(), id)
+	if err != nil {
+		log.Printf("Failed to record error for recurring transaction %d: %v", id, err)
+	}
 }
 
 func (s *Store) CreateAccount(ctx context.Context, acc *models.Account) error {
@@ -702,43 +966,48 @@ func (s *Store) CreateTransfer(ctx context.Context, txData *models.Transaction)
 	}
 	defer tx.Rollback(ctx) // Pastikan di-rollback jika ada error
 
-	// 3. Masukkan data ke tabel 'transactions'
-	queryInsert := `
-		INSERT INTO transactions 
-			(amount, type, category, description, date, account_id, destination_account_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, created_at`
+	// 3. Simpan transfer, kurangi saldo akun asal & tambah saldo akun tujuan
+	if err := insertTransaction(ctx, tx, txData); err != nil {
+		return fmt.Errorf("failed to create transfer: %w", err)
+	}
 
-	err = tx.QueryRow(ctx, queryInsert,
-		txData.Amount,
-		"transfer",
-		"Transfer", // Set kategori default
-		txData.Description,
-		txData.Date,
-		txData.AccountID,
-		*txData.DestinationAccountID, // Dereference pointer
-	).Scan(&txData.ID, &txData.CreatedAt)
+	// 4. Jika semua berhasil, commit
+	return tx.Commit(ctx)
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to insert transfer record: %w", err)
-	}
+// transactionFilterClause membuat kondisi WHERE tambahan (diawali " AND") untuk tabel
+// transactions beralias t. Parameter baru ditambahkan ke args.
+func transactionFilterClause(filter models.TransactionFilter, args []interface{}) (string, []interface{}) {
+	var clause strings.Builder
 
-	// 4. Update Akun Asal (Mengurangi Saldo)
-	// Kita gunakan helper 'updateAccountBalance' yang sudah kita buat
-	if err := updateAccountBalance(ctx, tx, txData.AccountID, -txData.Amount); err != nil {
-		return fmt.Errorf("failed to update source account: %w", err)
+	if !filter.StartDate.IsZero() {
+		args = append(args, filter.StartDate)
+		fmt.Fprintf(&clause, " AND t.date >= $%d", len(args))
 	}
-
-	// 5. Update Akun Tujuan (Menambah Saldo)
-	if err := updateAccountBalance(ctx, tx, *txData.DestinationAccountID, txData.Amount); err != nil {
-		return fmt.Errorf("failed to update destination account: %w", err)
+	if !filter.EndDate.IsZero() {
+		args = append(args, filter.EndDate)
+		fmt.Fprintf(&clause, " AND t.date <= $%d", len(args))
 	}
-
-	// 6. Jika semua berhasil, commit
-	return tx.Commit(ctx)
+	// Transfer masuk ikut ditampilkan saat memfilter satu akun
+	if filter.AccountID > 0 {
+		args = append(args, filter.AccountID)
+		fmt.Fprintf(&clause, " AND (t.account_id = $%d OR t.destination_account_id = $%d)", len(args), len(args))
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		fmt.Fprintf(&clause, " AND t.type = $%d", len(args))
+	}
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		fmt.Fprintf(&clause, " AND LOWER(t.category) = LOWER($%d)", len(args))
+	}
+	return clause.String(), args
 }
 
-func (s *Store) GetTransactionsForExport(ctx context.Context) ([]models.TransactionExport, error) {
+// StreamTransactionsForExport memanggil fn untuk setiap transaksi langsung dari hasil query,
+// tanpa menampung semuanya di memori. Berhenti jika fn mengembalikan error.
+func (s *Store) StreamTransactionsForExport(ctx context.Context, filter models.TransactionFilter, fn func(*models.TransactionExport) error) error {
+	where, args := transactionFilterClause(filter, nil)
 	query := `
 		SELECT 
 			t.id, t.amount, t.type, t.category, t.description, t.date, 
@@ -748,28 +1017,342 @@ func (s *Store) GetTransactionsForExport(ctx context.Context) ([]models.Transact
 		FROM transactions t
 		LEFT JOIN accounts a_src ON t.account_id = a_src.id
 		LEFT JOIN accounts a_dest ON t.destination_account_id = a_dest.id
-		ORDER BY t.date DESC
+		WHERE 1=1` + where + `
+		ORDER BY t.date DESC, t.id DESC
 	`
 
-	rows, err := s.Pool.Query(ctx, query)
+	rows, err := s.Pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	transactions := make([]models.TransactionExport, 0)
+	loc := calendarFrom(ctx).loc
+	var tx models.TransactionExport
 	for rows.Next() {
-		var tx models.TransactionExport
 		err := rows.Scan(
 			&tx.ID, &tx.Amount, &tx.Type, &tx.Category, &tx.Description,
 			&tx.Date, &tx.CreatedAt, &tx.AccountID, &tx.DestinationAccountID,
 			&tx.AccountName, &tx.DestinationAccountName,
 		)
 		if err != nil {
+			return err
+		}
+		tx.Date = tx.Date.In(loc)
+		if err := fn(&tx); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (s *Store) GetTransactionsForExport(ctx context.Context, filter models.TransactionFilter) ([]models.TransactionExport, error) {
+	transactions := make([]models.TransactionExport, 0)
+	err := s.StreamTransactionsForExport(ctx, filter, func(tx *models.TransactionExport) error {
+		transactions = append(transactions, *tx)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// GetAccountBalancesForPeriod menghitung saldo awal, arus kas dan saldo akhir setiap akun
+// untuk periode tertentu. Saldo akhir = current_balance dikurangi efek transaksi setelah endDate.
+func (s *Store) GetAccountBalancesForPeriod(ctx context.Context, startDate, endDate time.Time, accountID int64) ([]models.AccountPeriodBalance, error) {
+	query := `
+		SELECT
+			a.id, a.name, a.type, a.current_balance,
+			COALESCE(SUM(t.amount) FILTER (WHERE t.date >= $1 AND t.date <= $2 AND t.type = 'income'), 0),
+			COALESCE(SUM(t.amount) FILTER (WHERE t.date >= $1 AND t.date <= $2 AND t.type = 'expense'), 0),
+			COALESCE(SUM(t.amount) FILTER (WHERE t.date >= $1 AND t.date <= $2 AND t.type = 'transfer' AND t.destination_account_id = a.id), 0),
+			COALESCE(SUM(t.amount) FILTER (WHERE t.date >= $1 AND t.date <= $2 AND t.type = 'transfer' AND t.account_id = a.id), 0),
+			COALESCE(SUM(CASE
+				WHEN t.type = 'income' THEN t.amount
+				WHEN t.type = 'transfer' AND t.destination_account_id = a.id THEN t.amount
+				ELSE -t.amount
+			END) FILTER (WHERE t.date > $2), 0)
+		FROM accounts a
+		LEFT JOIN transactions t ON t.account_id = a.id OR t.destination_account_id = a.id
+	`
+	args := []interface{}{startDate, endDate}
+
+	if accountID > 0 {
+		query += " WHERE a.id = $3"
+		args = append(args, accountID)
+	}
+	query += " GROUP BY a.id ORDER BY a.name"
+
+	rows, err := s.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make([]models.AccountPeriodBalance, 0)
+	for rows.Next() {
+		var b models.AccountPeriodBalance
+		var current, afterEnd int64
+		if err := rows.Scan(&b.AccountID, &b.Name, &b.Type, &current,
+			&b.Income, &b.Expense, &b.TransferIn, &b.TransferOut, &afterEnd); err != nil {
 			return nil, err
 		}
-		transactions = append(transactions, tx)
+		b.ClosingBalance = current - afterEnd
+		b.OpeningBalance = b.ClosingBalance - (b.Income - b.Expense + b.TransferIn - b.TransferOut)
+		balances = append(balances, b)
 	}
 
-	return transactions, rows.Err()
+	return balances, rows.Err()
+}
+
+// GetBudgetVsActual mengambil budget yang periodenya beririsan dengan [startDate, endDate],
+// beserta total pengeluaran kategori tersebut selama periode budget (dibatasi periode dan akun).
+// Budget mingguan dilaporkan per bulan: amount per minggu x jumlah hari bulan itu / 7.
+func (s *Store) GetBudgetVsActual(ctx context.Context, startDate, endDate time.Time, accountID int64) ([]models.BudgetActual, error) {
+	cal := calendarFrom(ctx)
+	query := `
+		SELECT ` + budgetColumns + `
+		FROM budgets
+		WHERE (year * 12 + month - 1) BETWEEN $1 - 11 AND $2
+		ORDER BY year, month, category_name
+	`
+	rows, err := s.Pool.Query(ctx, query, monthIndex(cal.monthOf(startDate)), monthIndex(cal.monthOf(endDate)))
+	if err != nil {
+		return nil, err
+	}
+	budgets, err := scanBudgets(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.BudgetActual, 0)
+	var windows []spendWindow
+	for _, b := range budgets {
+		amount := b.Amount
+		var periodStart, periodEnd time.Time
+		if b.PeriodType == models.PeriodWeekly {
+			periodStart, periodEnd = cal.monthRange(b.Month, b.Year)
+			days, _ := elapsedDays(periodStart, periodEnd, periodStart)
+			amount = b.Amount * int64(days) / 7
+		} else {
+			periodStart, periodEnd = cal.periodWindow(b.PeriodType, b.PeriodStartDay, b.Month, b.Year, time.Time{})
+		}
+		if periodStart.After(endDate) || periodEnd.Before(startDate) {
+			continue
+		}
+		if periodStart.Before(startDate) {
+			periodStart = startDate
+		}
+		if periodEnd.After(endDate) {
+			periodEnd = endDate
+		}
+
+		results = append(results, models.BudgetActual{
+			CategoryName: b.CategoryName,
+			Month:        b.Month,
+			Year:         b.Year,
+			Budget:       amount,
+		})
+		windows = append(windows, spendWindow{Category: b.CategoryName, Start: periodStart, End: periodEnd})
+	}
+
+	spent, err := s.spendingForWindows(ctx, windows, accountID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Actual = spent[i]
+	}
+	return results, nil
+}
+
+// MaxBatchTransactions adalah jumlah baris maksimum per permintaan batch
+const MaxBatchTransactions = 5000
+
+// BatchValidationError dikembalikan jika ada baris yang tidak valid di dalam batch.
+// Tidak ada satu baris pun yang disimpan jika error ini muncul.
+type BatchValidationError struct {
+	Rows []models.BatchRowError
+}
+
+func (e *BatchValidationError) Error() string {
+	return fmt.Sprintf("batch contains %d invalid rows", len(e.Rows))
+}
+
+// validateTransactionRow memeriksa satu transaksi sebelum disimpan lewat batch.
+// Amount dinormalisasi menjadi positif dan kategori transfer diisi otomatis.
+func validateTransactionRow(txData *models.Transaction) error {
+	if txData.Amount < 0 {
+		txData.Amount = -txData.Amount
+	}
+	if txData.Amount == 0 {
+		return errors.New("amount cannot be zero")
+	}
+	if txData.AccountID <= 0 {
+		return errors.New("invalid account ID")
+	}
+	if txData.Date.IsZero() {
+		return errors.New("date is required")
+	}
+
+	switch txData.Type {
+	case "income", "expense":
+		if txData.Category == "" {
+			return errors.New("category is required")
+		}
+		txData.DestinationAccountID = nil
+	case "transfer":
+		if txData.DestinationAccountID == nil || *txData.DestinationAccountID <= 0 {
+			return errors.New("invalid destination account ID")
+		}
+		if txData.AccountID == *txData.DestinationAccountID {
+			return errors.New("source and destination accounts cannot be the same")
+		}
+		if txData.Category == "" {
+			txData.Category = "Transfer"
+		}
+	default:
+		return errors.New("type must be 'income', 'expense' or 'transfer'")
+	}
+
+	if len(txData.Category) > 50 {
+		return errors.New("category is longer than 50 characters")
+	}
+	if txData.ExternalID != nil {
+		if *txData.ExternalID == "" {
+			txData.ExternalID = nil
+		} else if len(*txData.ExternalID) > 255 {
+			return errors.New("external ID is longer than 255 characters")
+		}
+	}
+	return nil
+}
+
+// existingAccountIDs mengembalikan set ID akun yang benar-benar ada di database
+func (s *Store) existingAccountIDs(ctx context.Context, ids []int64) (map[int64]bool, error) {
+	rows, err := s.Pool.Query(ctx, `SELECT id FROM accounts WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make(map[int64]bool, len(ids))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		found[id] = true
+	}
+	return found, rows.Err()
+}
+
+// CreateTransactionsBatch memvalidasi SEMUA baris terlebih dahulu, lalu menyimpannya
+// sekaligus dengan COPY. Perubahan saldo dijumlahkan per akun sehingga setiap akun
+// hanya di-UPDATE satu kali. Semua baris berhasil, atau tidak sama sekali.
+func (s *Store) CreateTransactionsBatch(ctx context.Context, txs []models.Transaction) (int, error) {
+	if len(txs) == 0 {
+		return 0, errors.New("batch is empty")
+	}
+	if len(txs) > MaxBatchTransactions {
+		return 0, fmt.Errorf("batch exceeds the maximum of %d transactions", MaxBatchTransactions)
+	}
+
+	// 1. Validasi setiap baris
+	var rowErrors []models.BatchRowError
+	invalid := make(map[int]bool)
+	accountSet := make(map[int64]bool)
+	for i := range txs {
+		if err := validateTransactionRow(&txs[i]); err != nil {
+			rowErrors = append(rowErrors, models.BatchRowError{Index: i, Error: err.Error()})
+			invalid[i] = true
+			continue
+		}
+		accountSet[txs[i].AccountID] = true
+		if txs[i].DestinationAccountID != nil {
+			accountSet[*txs[i].DestinationAccountID] = true
+		}
+	}
+
+	// 2. Pastikan semua akun yang dirujuk memang ada
+	accountIDs := make([]int64, 0, len(accountSet))
+	for id := range accountSet {
+		accountIDs = append(accountIDs, id)
+	}
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+	if len(accountIDs) > 0 {
+		found, err := s.existingAccountIDs(ctx, accountIDs)
+		if err != nil {
+			return 0, err
+		}
+		for i, t := range txs {
+			if invalid[i] {
+				continue
+			}
+			if !found[t.AccountID] {
+				rowErrors = append(rowErrors, models.BatchRowError{Index: i, Error: "account not found"})
+			} else if t.DestinationAccountID != nil && !found[*t.DestinationAccountID] {
+				rowErrors = append(rowErrors, models.BatchRowError{Index: i, Error: "destination account not found"})
+			}
+		}
+	}
+
+	if len(rowErrors) > 0 {
+		sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Index < rowErrors[j].Index })
+		return 0, &BatchValidationError{Rows: rowErrors}
+	}
+
+	// 3. Hitung total perubahan saldo per akun
+	balanceChanges := make(map[int64]int64)
+	for _, t := range txs {
+		switch t.Type {
+		case "income":
+			balanceChanges[t.AccountID] += t.Amount
+		case "expense":
+			balanceChanges[t.AccountID] -= t.Amount
+		case "transfer":
+			balanceChanges[t.AccountID] -= t.Amount
+			balanceChanges[*t.DestinationAccountID] += t.Amount
+		}
+	}
+
+	// 4. Mulai Transaksi
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// 5. Masukkan semua baris dengan COPY
+	columns := []string{"amount", "type", "category", "description", "date", "account_id", "destination_account_id", "external_id"}
+	copied, err := tx.CopyFrom(ctx, pgx.Identifier{"transactions"}, columns,
+		pgx.CopyFromSlice(len(txs), func(i int) ([]any, error) {
+			t := txs[i]
+			return []any{t.Amount, t.Type, t.Category, t.Description, t.Date, t.AccountID, t.DestinationAccountID, t.ExternalID}, nil
+		}),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy transactions: %w", err)
+	}
+
+	// 6. Satu UPDATE per akun. Diurutkan berdasarkan ID agar urutan lock selalu sama.
+	for _, id := range accountIDs {
+		if balanceChanges[id] == 0 {
+			continue
+		}
+		if err := updateAccountBalance(ctx, tx, id, balanceChanges[id]); err != nil {
+			return 0, fmt.Errorf("failed to update balance of account %d: %w", id, err)
+		}
+	}
+
+	// 7. Commit
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	// 8. Cek ambang budget, sekali per kategori & tanggal pengeluaran
+	s.checkBudgetAlertsBatch(ctx, txs)
+	return int(copied), nil
 }
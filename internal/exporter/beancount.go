@@ -0,0 +1,51 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteBeancount menulis jurnal Beancount lengkap dengan direktif open dan balance.
+// Direktif balance dicek di AWAL hari, jadi ditulis sehari setelah transaksi terakhir.
+func WriteBeancount(w io.Writer, j Journal) error {
+	p := prepare(j)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "; Diekspor dari Finance Tracker pada %s\n\n", j.Now.Format(dateLayout))
+	fmt.Fprintf(bw, "option \"operating_currency\" \"%s\"\n\n", Commodity)
+	for _, name := range p.sortedAccounts() {
+		fmt.Fprintf(bw, "%s open %s %s\n", p.openDates[name].Format(dateLayout), name, Commodity)
+	}
+	bw.WriteString("\n")
+
+	writeEntry := func(e entry) {
+		fmt.Fprintf(bw, "%s * %s\n", e.date.Format(dateLayout), beancountString(cleanText(e.description)))
+		if e.id > 0 {
+			fmt.Fprintf(bw, "  id: \"%d\"\n", e.id)
+		}
+		for _, ps := range e.postings {
+			fmt.Fprintf(bw, "  %-*s  %s %s\n", accountWidth, ps.account, FormatAmount(ps.amount), Commodity)
+		}
+		bw.WriteString("\n")
+	}
+	for _, e := range p.opening {
+		writeEntry(e)
+	}
+	for _, e := range p.entries {
+		writeEntry(e)
+	}
+
+	balanceDate := p.asOf.AddDate(0, 0, 1).Format(dateLayout)
+	for _, bal := range p.balances {
+		fmt.Fprintf(bw, "%s balance %-*s  %s %s\n", balanceDate, accountWidth, bal.account, FormatAmount(bal.amount), Commodity)
+	}
+
+	return bw.Flush()
+}
+
+// beancountString menulis string bertanda kutip dengan escape untuk '"' dan '\'
+func beancountString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,88 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CalendarEvent adalah satu acara sepanjang hari (all-day) di feed iCalendar
+type CalendarEvent struct {
+	UID         string
+	Date        time.Time // Hanya tanggal kalendernya yang dipakai
+	Summary     string
+	Description string
+	RRule       string // Kosong untuk acara sekali
+}
+
+// Calendar adalah isi satu feed iCalendar (RFC 5545)
+type Calendar struct {
+	Name     string
+	Timezone string // Nama zona waktu IANA, untuk X-WR-TIMEZONE
+	Events   []CalendarEvent
+	Now      time.Time // DTSTAMP semua acara
+}
+
+// WriteICal menulis kalender sebagai file .ics. Acara ditulis sebagai acara sepanjang hari
+// sehingga tidak bergeser di zona waktu perangkat.
+func WriteICal(w io.Writer, cal Calendar) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) { writeICalLine(bw, s) }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Finance Tracker//Recurring Transactions//ID")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + icalText(cal.Name))
+	if cal.Timezone != "" {
+		line("X-WR-TIMEZONE:" + cal.Timezone)
+	}
+	// Saran seberapa sering aplikasi kalender mengambil ulang feed ini
+	line("REFRESH-INTERVAL;VALUE=DURATION:PT6H")
+	line("X-PUBLISHED-TTL:PT6H")
+
+	stamp := cal.Now.UTC().Format("20060102T150405Z")
+	for _, ev := range cal.Events {
+		line("BEGIN:VEVENT")
+		line("UID:" + ev.UID)
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + ev.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + ev.Date.AddDate(0, 0, 1).Format("20060102"))
+		if ev.RRule != "" {
+			line("RRULE:" + ev.RRule)
+		}
+		line("SUMMARY:" + icalText(ev.Summary))
+		if ev.Description != "" {
+			line("DESCRIPTION:" + icalText(ev.Description))
+		}
+		line("TRANSP:TRANSPARENT") // Tidak menandai waktu sebagai sibuk
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// icalText meng-escape nilai TEXT sesuai RFC 5545
+func icalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "").Replace(s)
+}
+
+// writeICalLine menulis satu baris diakhiri CRLF, dilipat setiap 75 oktet tanpa memotong
+// karakter UTF-8 (baris lanjutan diawali satu spasi)
+func writeICalLine(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		fmt.Fprintf(w, "%s\r\n ", s[:cut])
+		s = s[cut:]
+		limit = 74 // Spasi di awal baris lanjutan ikut dihitung
+	}
+	fmt.Fprintf(w, "%s\r\n", s)
+}
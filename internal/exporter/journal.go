@@ -0,0 +1,236 @@
+// Package exporter mengubah data transaksi menjadi format file untuk aplikasi lain
+// (ledger-cli, hledger, Beancount, Excel, PDF, iCalendar). Package api yang mengambil data dari database.
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+const (
+	Commodity             = "IDR"
+	openingBalanceAccount = "Equity:Opening-Balances"
+	dateLayout            = "2006-01-02"
+)
+
+// Journal adalah semua data yang dibutuhkan untuk menulis jurnal plain-text accounting
+type Journal struct {
+	Accounts     []models.Account
+	Transactions []models.TransactionExport
+	Now          time.Time // Tanggal saldo akhir jika tidak ada transaksi setelahnya
+}
+
+// posting adalah satu baris akun + nominal di dalam entri jurnal
+type posting struct {
+	account string
+	amount  int64 // dalam 'sen'
+}
+
+type entry struct {
+	id          int64
+	date        time.Time
+	description string
+	postings    []posting
+}
+
+// prepared adalah jurnal yang sudah diterjemahkan ke akun berhierarki
+type prepared struct {
+	accountNames map[int64]string
+	openDates    map[string]time.Time // Tanggal pertama setiap akun dipakai
+	opening      []entry              // Saldo awal per akun
+	entries      []entry
+	balances     []posting // Saldo akhir (current_balance) per akun
+	firstDate    time.Time
+	asOf         time.Time // Tanggal transaksi terakhir (atau Now)
+}
+
+// accountComponent membuat satu komponen nama akun yang valid untuk Beancount
+// dan ledger: diawali huruf besar/angka, hanya berisi huruf, angka dan '-'.
+func accountComponent(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range name {
+		if (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	s := strings.TrimRight(b.String(), "-")
+	if s == "" {
+		return "Lainnya"
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// accountPrefix memetakan accounts.type ke hierarki akun
+func accountPrefix(accountType string) string {
+	switch strings.ToLower(accountType) {
+	case "bank":
+		return "Assets:Bank"
+	case "cash":
+		return "Assets:Cash"
+	case "e-wallet", "ewallet":
+		return "Assets:EWallet"
+	case "credit", "credit card", "kartu kredit":
+		return "Liabilities:CreditCard"
+	}
+	return "Assets"
+}
+
+func categoryAccount(txType, category string) string {
+	if txType == "income" {
+		return "Income:" + accountComponent(category)
+	}
+	return "Expenses:" + accountComponent(category)
+}
+
+// FormatAmount menulis 'sen' sebagai angka desimal dengan 2 digit, misal -1234.50
+func FormatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func prepare(j Journal) prepared {
+	p := prepared{
+		accountNames: make(map[int64]string),
+		openDates:    make(map[string]time.Time),
+	}
+
+	// 1. Nama akun, dibuat unik jika dua akun punya nama yang sama setelah dibersihkan
+	used := make(map[string]bool)
+	for _, acc := range j.Accounts {
+		name := accountPrefix(acc.Type) + ":" + accountComponent(acc.Name)
+		if used[name] {
+			name = fmt.Sprintf("%s-%d", name, acc.ID)
+		}
+		used[name] = true
+		p.accountNames[acc.ID] = name
+	}
+	accountName := func(id int64) string {
+		if name, ok := p.accountNames[id]; ok {
+			return name
+		}
+		return fmt.Sprintf("Assets:Unknown-%d", id)
+	}
+	markUsed := func(account string, date time.Time) {
+		if first, ok := p.openDates[account]; !ok || date.Before(first) {
+			p.openDates[account] = date
+		}
+	}
+
+	// 2. Transaksi diurutkan dari yang paling lama
+	txs := make([]models.TransactionExport, len(j.Transactions))
+	copy(txs, j.Transactions)
+	sort.SliceStable(txs, func(a, b int) bool {
+		if !txs[a].Date.Equal(txs[b].Date) {
+			return txs[a].Date.Before(txs[b].Date)
+		}
+		return txs[a].ID < txs[b].ID
+	})
+
+	effects := make(map[int64]int64) // Total perubahan saldo dari transaksi, per akun
+	p.asOf = day(j.Now)
+	for _, tx := range txs {
+		date := day(tx.Date)
+		source := accountName(tx.AccountID)
+		e := entry{id: tx.ID, date: date, description: tx.Description}
+		if e.description == "" {
+			e.description = tx.Category
+		}
+
+		switch tx.Type {
+		case "income":
+			e.postings = []posting{{source, tx.Amount}, {categoryAccount("income", tx.Category), -tx.Amount}}
+			effects[tx.AccountID] += tx.Amount
+		case "expense":
+			e.postings = []posting{{categoryAccount("expense", tx.Category), tx.Amount}, {source, -tx.Amount}}
+			effects[tx.AccountID] -= tx.Amount
+		case "transfer":
+			if !tx.DestinationAccountID.Valid {
+				continue
+			}
+			dest := accountName(tx.DestinationAccountID.Int64)
+			e.postings = []posting{{dest, tx.Amount}, {source, -tx.Amount}}
+			effects[tx.AccountID] -= tx.Amount
+			effects[tx.DestinationAccountID.Int64] += tx.Amount
+		default:
+			continue
+		}
+
+		for _, ps := range e.postings {
+			markUsed(ps.account, date)
+		}
+		if p.firstDate.IsZero() || date.Before(p.firstDate) {
+			p.firstDate = date
+		}
+		if date.After(p.asOf) {
+			p.asOf = date
+		}
+		p.entries = append(p.entries, e)
+	}
+
+	// 3. Tanggal buka akun: tanggal dibuat atau transaksi pertama, mana yang lebih dulu
+	for _, acc := range j.Accounts {
+		if acc.CreatedAt.IsZero() {
+			continue
+		}
+		created := day(acc.CreatedAt)
+		markUsed(accountName(acc.ID), created)
+		if p.firstDate.IsZero() || created.Before(p.firstDate) {
+			p.firstDate = created
+		}
+	}
+	if p.firstDate.IsZero() {
+		p.firstDate = p.asOf
+	}
+
+	// 4. Saldo awal = current_balance dikurangi efek semua transaksi
+	for _, acc := range j.Accounts {
+		name := accountName(acc.ID)
+		if _, ok := p.openDates[name]; !ok {
+			markUsed(name, p.firstDate)
+		}
+		if opening := acc.CurrentBalance - effects[acc.ID]; opening != 0 {
+			p.opening = append(p.opening, entry{
+				date:        p.openDates[name],
+				description: "Saldo awal " + acc.Name,
+				postings:    []posting{{name, opening}, {openingBalanceAccount, -opening}},
+			})
+		}
+		p.balances = append(p.balances, posting{name, acc.CurrentBalance})
+	}
+	if len(p.opening) > 0 {
+		markUsed(openingBalanceAccount, p.firstDate)
+	}
+	return p
+}
+
+// sortedAccounts mengembalikan semua akun yang dipakai, urut berdasarkan nama
+func (p prepared) sortedAccounts() []string {
+	names := make([]string, 0, len(p.openDates))
+	for name := range p.openDates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cleanText membuang baris baru dan spasi ganda dari deskripsi
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,59 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// accountWidth adalah lebar kolom akun agar nominal sejajar
+const accountWidth = 48
+
+// WriteLedger menulis jurnal yang bisa dibaca ledger-cli maupun hledger.
+// Saldo akhir setiap akun ditulis sebagai balance assertion ("0 IDR = saldo").
+func WriteLedger(w io.Writer, j Journal) error {
+	p := prepare(j)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "; Diekspor dari Finance Tracker pada %s\n\n", j.Now.Format(dateLayout))
+	fmt.Fprintf(bw, "commodity %s\n    format 1000.00 %s\n\n", Commodity, Commodity)
+	for _, name := range p.sortedAccounts() {
+		fmt.Fprintf(bw, "account %s\n", name)
+	}
+	bw.WriteString("\n")
+
+	writeEntry := func(e entry) {
+		fmt.Fprintf(bw, "%s * %s\n", e.date.Format(dateLayout), ledgerPayee(e.description))
+		if e.id > 0 {
+			fmt.Fprintf(bw, "    ; id: %d\n", e.id)
+		}
+		for _, ps := range e.postings {
+			fmt.Fprintf(bw, "    %-*s  %s %s\n", accountWidth, ps.account, FormatAmount(ps.amount), Commodity)
+		}
+		bw.WriteString("\n")
+	}
+	for _, e := range p.opening {
+		writeEntry(e)
+	}
+	for _, e := range p.entries {
+		writeEntry(e)
+	}
+
+	fmt.Fprintf(bw, "%s * Saldo akhir\n", p.asOf.Format(dateLayout))
+	for _, bal := range p.balances {
+		fmt.Fprintf(bw, "    %-*s  0 %s = %s %s\n", accountWidth, bal.account, Commodity, FormatAmount(bal.amount), Commodity)
+	}
+
+	return bw.Flush()
+}
+
+// ledgerPayee membersihkan deskripsi. ';' setelah dua spasi akan dianggap komentar,
+// jadi spasi ganda dan baris baru dihilangkan.
+func ledgerPayee(s string) string {
+	s = cleanText(s)
+	if s == "" {
+		return "(tanpa deskripsi)"
+	}
+	return strings.ReplaceAll(s, "|", "/") // '|' memisahkan payee dan note di hledger
+}
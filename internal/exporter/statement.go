@@ -0,0 +1,259 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+	"github.com/jung-kurt/gofpdf"
+)
+
+var indonesianMonths = []string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// Statement adalah data laporan rekening bulanan. AccountID 0 berarti semua akun,
+// sehingga transfer antar akun tidak mengubah saldo gabungan. StartDate & EndDate adalah
+// batas bulan keuangan, yang bisa berbeda dari bulan kalender.
+type Statement struct {
+	Month          int
+	Year           int
+	StartDate      time.Time
+	EndDate        time.Time
+	AccountID      int64
+	AccountName    string
+	OpeningBalance int64
+	ClosingBalance int64
+	Summary        models.Summary
+	Transactions   []models.TransactionExport
+	Categories     []models.CategorySummary
+	Budgets        []models.BudgetActual
+	GeneratedAt    time.Time
+}
+
+// FormatRupiah menulis 'sen' dengan format Indonesia, misal "Rp 1.234.567,89"
+func FormatRupiah(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	digits := fmt.Sprintf("%d", cents/100)
+	var b strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	return fmt.Sprintf("%sRp %s,%02d", sign, b.String(), cents%100)
+}
+
+// statementEffect menghitung perubahan saldo sebuah transaksi bagi akun di laporan
+func statementEffect(tx models.TransactionExport, accountID int64) int64 {
+	switch tx.Type {
+	case "income":
+		return tx.Amount
+	case "expense":
+		return -tx.Amount
+	case "transfer":
+		if accountID == 0 {
+			return 0 // Pindah antar akun sendiri
+		}
+		if tx.DestinationAccountID.Valid && tx.DestinationAccountID.Int64 == accountID {
+			return tx.Amount
+		}
+		return -tx.Amount
+	}
+	return 0
+}
+
+// pdfTable menulis tabel sederhana dan mengulang header di setiap halaman baru
+type pdfTable struct {
+	pdf     *gofpdf.Fpdf
+	tr      func(string) string
+	widths  []float64
+	aligns  []string
+	headers []string
+}
+
+func (t *pdfTable) header() {
+	t.pdf.SetFont("Helvetica", "B", 8)
+	t.pdf.SetFillColor(217, 225, 242)
+	for i, h := range t.headers {
+		t.pdf.CellFormat(t.widths[i], 6, t.tr(h), "B", 0, t.aligns[i], true, 0, "")
+	}
+	t.pdf.Ln(-1)
+	t.pdf.SetFont("Helvetica", "", 8)
+}
+
+func (t *pdfTable) row(bold bool, cells ...string) {
+	_, pageHeight := t.pdf.GetPageSize()
+	_, _, _, bottom := t.pdf.GetMargins()
+	if t.pdf.GetY()+5 > pageHeight-bottom {
+		t.pdf.AddPage()
+		t.header()
+	}
+	if bold {
+		t.pdf.SetFont("Helvetica", "B", 8)
+	}
+	for i, c := range cells {
+		t.pdf.CellFormat(t.widths[i], 5, t.fit(t.tr(c), t.widths[i]-2), "", 0, t.aligns[i], false, 0, "")
+	}
+	t.pdf.Ln(-1)
+	if bold {
+		t.pdf.SetFont("Helvetica", "", 8)
+	}
+}
+
+// fit memotong teks yang terlalu panjang untuk lebar kolom
+func (t *pdfTable) fit(s string, width float64) string {
+	if t.pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	for len(s) > 0 && t.pdf.GetStringWidth(s+"...") > width {
+		s = s[:len(s)-1]
+	}
+	return s + "..."
+}
+
+func sectionTitle(pdf *gofpdf.Fpdf, title string) {
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 7, title, "", 1, "L", false, 0, "")
+}
+
+// WriteStatementPDF menulis laporan rekening bulanan: ringkasan saldo, semua transaksi
+// dengan saldo berjalan, rincian kategori pengeluaran dan status budget.
+func WriteStatementPDF(w io.Writer, st Statement) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(false, 15)
+	pdf.AliasNbPages("")
+	tr := pdf.UnicodeTranslatorFromDescriptor("") // Font bawaan PDF memakai cp1252
+
+	period := fmt.Sprintf("%s %d", indonesianMonths[st.Month-1], st.Year)
+	pdf.SetTitle("Laporan Rekening "+period, true)
+	pdf.SetCreator("Finance Tracker", true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 7)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Dibuat %s - Halaman %d/{nb}", st.GeneratedAt.Format("02-01-2006 15:04"), pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	// 1. Judul
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 9, "Laporan Rekening Bulanan", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	account := st.AccountName
+	if st.AccountID == 0 {
+		account = "Semua Akun"
+	}
+	pdf.CellFormat(0, 5, tr(fmt.Sprintf("Periode: %s (%s s/d %s)", period, st.StartDate.Format("02-01-2006"), st.EndDate.Format("02-01-2006"))), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, tr("Akun: "+account), "", 1, "L", false, 0, "")
+
+	// 2. Ringkasan
+	sectionTitle(pdf, "Ringkasan")
+	summary := &pdfTable{pdf: pdf, tr: tr, widths: []float64{60, 50}, aligns: []string{"L", "R"}}
+	pdf.SetFont("Helvetica", "", 9)
+	summary.row(false, "Saldo awal", FormatRupiah(st.OpeningBalance))
+	summary.row(false, "Total pemasukan", FormatRupiah(st.Summary.TotalIncome))
+	summary.row(false, "Total pengeluaran", FormatRupiah(st.Summary.TotalExpense))
+	summary.row(true, "Saldo akhir", FormatRupiah(st.ClosingBalance))
+
+	// 3. Transaksi dengan saldo berjalan, dari yang paling lama
+	sectionTitle(pdf, "Transaksi")
+	txs := make([]models.TransactionExport, len(st.Transactions))
+	copy(txs, st.Transactions)
+	sort.SliceStable(txs, func(a, b int) bool {
+		if !txs[a].Date.Equal(txs[b].Date) {
+			return txs[a].Date.Before(txs[b].Date)
+		}
+		return txs[a].ID < txs[b].ID
+	})
+
+	table := &pdfTable{
+		pdf: pdf, tr: tr,
+		widths:  []float64{18, 58, 28, 25, 25, 26},
+		aligns:  []string{"L", "L", "L", "R", "R", "R"},
+		headers: []string{"Tanggal", "Deskripsi", "Kategori", "Masuk", "Keluar", "Saldo"},
+	}
+	table.header()
+	table.row(false, "", "Saldo awal", "", "", "", FormatRupiah(st.OpeningBalance))
+	balance := st.OpeningBalance
+	for _, tx := range txs {
+		effect := statementEffect(tx, st.AccountID)
+		balance += effect
+
+		description := tx.Description
+		if tx.Type == "transfer" {
+			description = strings.TrimSpace(fmt.Sprintf("%s (%s -> %s)", description, tx.AccountName.String, tx.DestinationAccountName.String))
+		}
+		in, out := "", ""
+		switch {
+		case effect > 0:
+			in = FormatRupiah(effect)
+		case effect < 0:
+			out = FormatRupiah(-effect)
+		default:
+			in, out = "-", "-" // Transfer antar akun sendiri
+		}
+		table.row(false, tx.Date.Format("02-01-2006"), description, tx.Category, in, out, FormatRupiah(balance))
+	}
+	table.row(true, "", "Saldo akhir", "", "", "", FormatRupiah(st.ClosingBalance))
+
+	// 4. Rincian kategori pengeluaran
+	sectionTitle(pdf, "Pengeluaran per Kategori")
+	categories := &pdfTable{
+		pdf: pdf, tr: tr,
+		widths:  []float64{80, 50, 25},
+		aligns:  []string{"L", "R", "R"},
+		headers: []string{"Kategori", "Total", "Persentase"},
+	}
+	categories.header()
+	var totalExpense int64
+	for _, cs := range st.Categories {
+		totalExpense += cs.TotalAmount
+	}
+	for _, cs := range st.Categories {
+		share := 0.0
+		if totalExpense > 0 {
+			share = float64(cs.TotalAmount) * 100 / float64(totalExpense)
+		}
+		categories.row(false, cs.Category, FormatRupiah(cs.TotalAmount), fmt.Sprintf("%.1f%%", share))
+	}
+	if len(st.Categories) == 0 {
+		categories.row(false, "Tidak ada pengeluaran", "", "")
+	}
+
+	// 5. Status budget
+	sectionTitle(pdf, "Status Budget")
+	budgets := &pdfTable{
+		pdf: pdf, tr: tr,
+		widths:  []float64{50, 30, 30, 30, 40},
+		aligns:  []string{"L", "R", "R", "R", "L"},
+		headers: []string{"Kategori", "Budget", "Realisasi", "Sisa", "Status"},
+	}
+	budgets.header()
+	for _, b := range st.Budgets {
+		status := "Aman"
+		switch {
+		case b.Actual > b.Budget:
+			status = "Melebihi budget"
+		case b.Budget > 0 && b.Actual*100 >= b.Budget*80:
+			status = "Hampir habis"
+		}
+		budgets.row(false, b.CategoryName, FormatRupiah(b.Budget), FormatRupiah(b.Actual), FormatRupiah(b.Budget-b.Actual), status)
+	}
+	if len(st.Budgets) == 0 {
+		budgets.row(false, "Belum ada budget", "", "", "", "")
+	}
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,293 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// Format angka Rupiah (locale id-ID) dan tanggal untuk sel Excel
+const (
+	currencyFormat = `[$Rp-421]\ #,##0.00;\-[$Rp-421]\ #,##0.00`
+	dateFormat     = "yyyy-mm-dd"
+	monthFormat    = "mmm yyyy"
+	percentFormat  = "0.0%"
+)
+
+// Workbook adalah data untuk file XLSX: satu sheet untuk setiap bagian laporan
+type Workbook struct {
+	StartDate    time.Time
+	EndDate      time.Time
+	Transactions []models.TransactionExport
+	Categories   []models.CategorySummary
+	Accounts     []models.AccountPeriodBalance
+	Budgets      []models.BudgetActual
+}
+
+// xlsxStyles menyimpan ID style yang dipakai bersama oleh semua sheet
+type xlsxStyles struct {
+	header, date, month, currency, percent, total, totalCurrency int
+}
+
+func newXLSXStyles(f *excelize.File) (xlsxStyles, error) {
+	var st xlsxStyles
+	currency, date, month, percent := currencyFormat, dateFormat, monthFormat, percentFormat
+	bold := &excelize.Font{Bold: true}
+
+	defs := []struct {
+		id    *int
+		style *excelize.Style
+	}{
+		{&st.header, &excelize.Style{
+			Font:      bold,
+			Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9E1F2"}},
+			Border:    []excelize.Border{{Type: "bottom", Color: "000000", Style: 1}},
+			Alignment: &excelize.Alignment{Vertical: "center"},
+		}},
+		{&st.date, &excelize.Style{CustomNumFmt: &date}},
+		{&st.month, &excelize.Style{CustomNumFmt: &month}},
+		{&st.currency, &excelize.Style{CustomNumFmt: &currency}},
+		{&st.percent, &excelize.Style{CustomNumFmt: &percent}},
+		{&st.total, &excelize.Style{Font: bold}},
+		{&st.totalCurrency, &excelize.Style{Font: bold, CustomNumFmt: &currency}},
+	}
+	for _, d := range defs {
+		id, err := f.NewStyle(d.style)
+		if err != nil {
+			return st, err
+		}
+		*d.id = id
+	}
+	return st, nil
+}
+
+// rupiah mengubah 'sen' menjadi nilai numerik untuk sel berformat mata uang
+func rupiah(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+// sheetWriter membungkus StreamWriter agar setiap sheet cukup menulis baris demi baris
+type sheetWriter struct {
+	sw  *excelize.StreamWriter
+	row int
+}
+
+func newSheet(f *excelize.File, name string, widths []float64, header []string, headerStyle int) (*sheetWriter, error) {
+	if _, err := f.NewSheet(name); err != nil {
+		return nil, err
+	}
+	sw, err := f.NewStreamWriter(name)
+	if err != nil {
+		return nil, err
+	}
+	for i, width := range widths {
+		if err := sw.SetColWidth(i+1, i+1, width); err != nil {
+			return nil, err
+		}
+	}
+	// Baris header tetap terlihat saat di-scroll
+	if err := sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return nil, err
+	}
+
+	s := &sheetWriter{sw: sw}
+	cells := make([]interface{}, len(header))
+	for i, h := range header {
+		cells[i] = excelize.Cell{StyleID: headerStyle, Value: h}
+	}
+	return s, s.add(cells...)
+}
+
+func (s *sheetWriter) add(values ...interface{}) error {
+	s.row++
+	cell, err := excelize.CoordinatesToCellName(1, s.row)
+	if err != nil {
+		return err
+	}
+	return s.sw.SetRow(cell, values)
+}
+
+// sumFormula menjumlahkan satu kolom dari baris 2 sampai baris data terakhir.
+// Harus dipanggil sebelum baris total ditulis; kosong jika belum ada data.
+func (s *sheetWriter) sumFormula(col string) string {
+	if s.row < 2 {
+		return ""
+	}
+	return fmt.Sprintf("SUM(%s2:%s%d)", col, col, s.row)
+}
+
+// WriteXLSX menulis workbook berisi sheet Transaksi, Per Kategori, Saldo Akun
+// dan Budget vs Realisasi. Nominal ditulis sebagai angka dengan format Rupiah
+// dan tanggal sebagai sel tanggal, sehingga bisa langsung dihitung di Excel.
+func WriteXLSX(w io.Writer, wb Workbook) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	st, err := newXLSXStyles(f)
+	if err != nil {
+		return err
+	}
+
+	writers := []func(*excelize.File, xlsxStyles, Workbook) error{
+		writeTransactionsSheet,
+		writeCategorySheet,
+		writeAccountSheet,
+		writeBudgetSheet,
+	}
+	for _, write := range writers {
+		if err := write(f, st, wb); err != nil {
+			return err
+		}
+	}
+
+	// Sheet bawaan "Sheet1" tidak dipakai
+	if err := f.DeleteSheet("Sheet1"); err != nil {
+		return err
+	}
+	f.SetActiveSheet(0)
+	f.SetDocProps(&excelize.DocProperties{
+		Title:   fmt.Sprintf("Laporan Keuangan %s s/d %s", wb.StartDate.Format(dateLayout), wb.EndDate.Format(dateLayout)),
+		Creator: "Finance Tracker",
+	})
+
+	return f.Write(w)
+}
+
+func writeTransactionsSheet(f *excelize.File, st xlsxStyles, wb Workbook) error {
+	s, err := newSheet(f, "Transaksi",
+		[]float64{12, 12, 10, 18, 20, 40, 20, 20},
+		[]string{"ID Transaksi", "Tanggal", "Tipe", "Jumlah (Rp)", "Kategori", "Deskripsi", "Akun Asal", "Akun Tujuan"},
+		st.header)
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range wb.Transactions {
+		err := s.add(
+			tx.ID,
+			excelize.Cell{StyleID: st.date, Value: day(tx.Date)},
+			tx.Type,
+			excelize.Cell{StyleID: st.currency, Value: rupiah(tx.Amount)},
+			tx.Category,
+			tx.Description,
+			tx.AccountName.String,
+			tx.DestinationAccountName.String,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return s.sw.Flush()
+}
+
+func writeCategorySheet(f *excelize.File, st xlsxStyles, wb Workbook) error {
+	s, err := newSheet(f, "Per Kategori",
+		[]float64{24, 18, 12},
+		[]string{"Kategori", "Total Pengeluaran (Rp)", "Persentase"},
+		st.header)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, cs := range wb.Categories {
+		total += cs.TotalAmount
+	}
+	for _, cs := range wb.Categories {
+		share := 0.0
+		if total > 0 {
+			share = float64(cs.TotalAmount) / float64(total)
+		}
+		err := s.add(
+			cs.Category,
+			excelize.Cell{StyleID: st.currency, Value: rupiah(cs.TotalAmount)},
+			excelize.Cell{StyleID: st.percent, Value: share},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.add(
+		excelize.Cell{StyleID: st.total, Value: "Total"},
+		excelize.Cell{StyleID: st.totalCurrency, Formula: s.sumFormula("B"), Value: rupiah(total)},
+	)
+	if err != nil {
+		return err
+	}
+	return s.sw.Flush()
+}
+
+func writeAccountSheet(f *excelize.File, st xlsxStyles, wb Workbook) error {
+	s, err := newSheet(f, "Saldo Akun",
+		[]float64{24, 12, 18, 18, 18, 18, 18, 18},
+		[]string{"Akun", "Tipe", "Saldo Awal (Rp)", "Pemasukan (Rp)", "Pengeluaran (Rp)", "Transfer Masuk (Rp)", "Transfer Keluar (Rp)", "Saldo Akhir (Rp)"},
+		st.header)
+	if err != nil {
+		return err
+	}
+
+	money := func(cents int64) excelize.Cell {
+		return excelize.Cell{StyleID: st.currency, Value: rupiah(cents)}
+	}
+	var opening, closing int64
+	for _, acc := range wb.Accounts {
+		opening += acc.OpeningBalance
+		closing += acc.ClosingBalance
+		err := s.add(
+			acc.Name, acc.Type,
+			money(acc.OpeningBalance),
+			money(acc.Income),
+			money(acc.Expense),
+			money(acc.TransferIn),
+			money(acc.TransferOut),
+			money(acc.ClosingBalance),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.add(
+		excelize.Cell{StyleID: st.total, Value: "Total"}, nil,
+		excelize.Cell{StyleID: st.totalCurrency, Formula: s.sumFormula("C"), Value: rupiah(opening)},
+		nil, nil, nil, nil,
+		excelize.Cell{StyleID: st.totalCurrency, Formula: s.sumFormula("H"), Value: rupiah(closing)},
+	)
+	if err != nil {
+		return err
+	}
+	return s.sw.Flush()
+}
+
+func writeBudgetSheet(f *excelize.File, st xlsxStyles, wb Workbook) error {
+	s, err := newSheet(f, "Budget vs Realisasi",
+		[]float64{12, 24, 18, 18, 18, 12},
+		[]string{"Bulan", "Kategori", "Budget (Rp)", "Realisasi (Rp)", "Sisa (Rp)", "Terpakai"},
+		st.header)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range wb.Budgets {
+		used := 0.0
+		if b.Budget > 0 {
+			used = float64(b.Actual) / float64(b.Budget)
+		}
+		err := s.add(
+			excelize.Cell{StyleID: st.month, Value: time.Date(b.Year, time.Month(b.Month), 1, 0, 0, 0, 0, time.UTC)},
+			b.CategoryName,
+			excelize.Cell{StyleID: st.currency, Value: rupiah(b.Budget)},
+			excelize.Cell{StyleID: st.currency, Value: rupiah(b.Actual)},
+			excelize.Cell{StyleID: st.currency, Value: rupiah(b.Budget - b.Actual)},
+			excelize.Cell{StyleID: st.percent, Value: used},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return s.sw.Flush()
+}
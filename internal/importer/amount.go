@@ -0,0 +1,189 @@
+// Package importer berisi parser untuk file mutasi/ekspor dari bank dan aplikasi lain.
+// Semua parser menghasilkan []models.ImportRow yang kemudian disimpan oleh package api.
+package importer
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DecimalDot   = "dot"   // 1,234.56
+	DecimalComma = "comma" // 1.234,56
+
+	// Kategori default jika file tidak punya kolom kategori (sesuai seed migrasi 000002)
+	DefaultCategory  = "Lainnya"
+	TransferCategory = "Transfer"
+)
+
+// DateFormats adalah daftar layout tanggal yang dicoba saat deteksi otomatis.
+// Urutan penting: format Indonesia (hari/bulan) dicoba sebelum format Amerika.
+var DateFormats = []string{
+	"2006-01-02",
+	"02/01/2006",
+	"01/02/2006",
+	"2006/01/02",
+	"02-01-2006",
+	"02.01.2006",
+	"2006-01-02 15:04:05",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	time.RFC3339,
+	"02/01/06",
+}
+
+var errInvalidAmount = errors.New("invalid amount")
+
+// cleanAmount membuang simbol mata uang dan spasi, lalu mengembalikan angka beserta tandanya
+func cleanAmount(raw string) (string, bool) {
+	s := strings.TrimSpace(raw)
+	negative := false
+
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+
+	s = strings.NewReplacer("Rp.", "", "Rp", "", "rp", "", "IDR", "", " ", "", "\u00a0", "").Replace(s)
+
+	if strings.HasPrefix(s, "-") {
+		negative = !negative
+		s = s[1:]
+	} else if strings.HasSuffix(s, "-") {
+		negative = !negative
+		s = s[:len(s)-1]
+	}
+	s = strings.TrimPrefix(s, "+")
+	return s, negative
+}
+
+// ParseAmount mengubah teks nominal menjadi 'sen'. Hasilnya negatif jika teks diawali '-'
+// atau diapit tanda kurung. decimalStyle menentukan pemisah desimal ('dot' atau 'comma').
+func ParseAmount(raw string, decimalStyle string) (int64, error) {
+	s, negative := cleanAmount(raw)
+	if s == "" {
+		return 0, errInvalidAmount
+	}
+
+	if decimalStyle == DecimalComma {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.Replace(s, ",", ".", 1)
+	} else {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" {
+		intPart = "0"
+	}
+	if len(fracPart) > 2 {
+		// Lebih dari 2 digit desimal tidak bisa disimpan dalam 'sen'
+		if strings.TrimRight(fracPart[2:], "0") != "" {
+			return 0, errInvalidAmount
+		}
+		fracPart = fracPart[:2]
+	}
+	for len(fracPart) < 2 {
+		fracPart += "0"
+	}
+
+	whole, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return 0, errInvalidAmount
+	}
+	cents, err := strconv.ParseInt(fracPart, 10, 64)
+	if err != nil {
+		return 0, errInvalidAmount
+	}
+
+	amount := whole*100 + cents
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// DetectDecimalStyle menebak pemisah desimal dari contoh nilai nominal.
+// Nilai seperti "25.000" dianggap pemisah ribuan gaya Indonesia jika tidak ada
+// petunjuk lain. Jika tidak bisa dipastikan, dianggap 'dot' (format HandleExportCSV).
+func DetectDecimalStyle(values []string) string {
+	dotVotes, commaVotes := 0, 0
+	thousandDots, thousandCommas := 0, 0
+	for _, v := range values {
+		s, _ := cleanAmount(v)
+		lastDot := strings.LastIndex(s, ".")
+		lastComma := strings.LastIndex(s, ",")
+
+		switch {
+		case lastDot >= 0 && lastComma >= 0:
+			if lastComma > lastDot {
+				commaVotes++
+			} else {
+				dotVotes++
+			}
+		case lastComma >= 0:
+			if strings.Count(s, ",") > 1 {
+				dotVotes++ // 1,234,567 -> koma adalah pemisah ribuan
+			} else if len(s)-lastComma-1 != 3 {
+				commaVotes++
+			} else {
+				thousandCommas++
+			}
+		case lastDot >= 0:
+			if strings.Count(s, ".") > 1 {
+				commaVotes++ // 1.234.567 -> titik adalah pemisah ribuan
+			} else if len(s)-lastDot-1 != 3 {
+				dotVotes++
+			} else {
+				thousandDots++
+			}
+		}
+	}
+
+	if commaVotes == 0 && dotVotes == 0 && thousandDots > thousandCommas {
+		return DecimalComma
+	}
+	if commaVotes > dotVotes {
+		return DecimalComma
+	}
+	return DecimalDot
+}
+
+// DetectDateFormat mengembalikan layout pertama dari DateFormats yang bisa
+// mem-parsing SEMUA nilai yang tidak kosong. String kosong berarti tidak ada yang cocok.
+func DetectDateFormat(values []string) string {
+	for _, layout := range DateFormats {
+		matched := 0
+		ok := true
+		for _, v := range values {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			if _, err := time.Parse(layout, v); err != nil {
+				ok = false
+				break
+			}
+			matched++
+		}
+		if ok && matched > 0 {
+			return layout
+		}
+	}
+	return ""
+}
+
+// ParseType mengenali penanda tipe transaksi dalam bahasa Inggris maupun Indonesia
+func ParseType(raw string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "income", "pemasukan", "masuk", "cr", "kredit", "credit":
+		return "income", true
+	case "expense", "pengeluaran", "keluar", "db", "debit", "debet":
+		return "expense", true
+	case "transfer":
+		return "transfer", true
+	}
+	return "", false
+}
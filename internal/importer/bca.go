@@ -0,0 +1,85 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// bcaImporter membaca file CSV "Mutasi Rekening" dari KlikBCA:
+//
+//	No. rekening : ,'1234567890
+//	Periode : ,01/10/2024 - 31/10/2024
+//	Tanggal Transaksi,Keterangan,Cabang,Jumlah,,Saldo
+//	'01/10,'TRSF E-BANKING DB ...,'0000,"150,000.00",DB,"1,234,567.89"
+//	'PEND,'BI-FAST CR ...,'0000,"100,000.00",CR,
+//	Saldo Akhir,:,"1,084,567.89"
+//
+// Tanggal transaksi tidak punya tahun, jadi tahun diambil dari baris "Periode".
+type bcaImporter struct{}
+
+var bcaColumns = bankColumns{
+	date:        []string{"tanggal transaksi", "tanggal", "tgl"},
+	description: []string{"keterangan"},
+	amount:      []string{"jumlah", "mutasi"},
+	marker:      []string{"", "db cr"},
+	balance:     []string{"saldo"},
+}
+
+func (bcaImporter) Name() string { return "bca" }
+
+func (bcaImporter) Detect(data []byte) bool {
+	head := strings.ToLower(string(data[:min(len(data), 4096)]))
+	return strings.Contains(head, "cabang") && strings.Contains(head, "keterangan") && hasHeader(data, bcaColumns)
+}
+
+func (bcaImporter) Parse(data []byte) (*Statement, error) {
+	records, lines, err := readCSVLines(data, DetectDelimiter(data))
+	if err != nil {
+		return nil, err
+	}
+
+	headerRow, idx, ok := locateBankHeader(records, bcaColumns)
+	if !ok {
+		return nil, errors.New("BCA statement header not found")
+	}
+
+	stmt := &Statement{Currency: "IDR"}
+	var periodStart, periodEnd time.Time
+
+	// Baris informasi rekening di atas tabel
+	for _, rec := range records[:headerRow] {
+		label := normalizeHeader(column(rec, 0))
+		value := stripQuote(column(rec, 1))
+		switch {
+		case strings.HasPrefix(label, "no rekening"):
+			stmt.AccountNumber = value
+		case strings.HasPrefix(label, "periode"):
+			if from, to, found := strings.Cut(value, "-"); found {
+				periodStart, _ = ParseIndonesianDate(strings.TrimSpace(from), 0)
+				periodEnd, _ = ParseIndonesianDate(strings.TrimSpace(to), 0)
+			}
+		}
+	}
+
+	opts := bankTableOptions{
+		bank: "bca",
+		defaultYear: func(month time.Month) int {
+			switch {
+			case periodEnd.IsZero():
+				return time.Now().Year()
+			case month < periodStart.Month() && periodStart.Year() != periodEnd.Year():
+				// Periode melewati tahun baru, misal 15/12/2024 - 14/01/2025
+				return periodEnd.Year()
+			case periodStart.IsZero():
+				return periodEnd.Year()
+			default:
+				return periodStart.Year()
+			}
+		},
+	}
+	stmt.LedgerDate = periodEnd
+	parseBankTable(records, lines, headerRow, idx, opts, stmt)
+
+	return stmt, nil
+}
@@ -0,0 +1,52 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+)
+
+// bniImporter membaca ekspor mutasi BNI Internet Banking / BNI Mobile:
+//
+//	No. Rekening,0123456789
+//	Tanggal Transaksi,Uraian Transaksi,Tipe,Nominal,Saldo
+//	01-Okt-2024 10:11:12,TRANSFER DARI BUDI,Cr,"1.000.000,00","2.000.000,00"
+//	02-Okt-2024 08:00:00,PEMBAYARAN PLN,Db,"250.000,00","1.750.000,00"
+type bniImporter struct{}
+
+var bniColumns = bankColumns{
+	date:        []string{"tanggal transaksi", "tanggal", "tgl transaksi", "post date"},
+	description: []string{"uraian transaksi", "uraian", "keterangan", "description"},
+	amount:      []string{"nominal", "jumlah", "amount", "mutasi"},
+	marker:      []string{"tipe", "db cr", "d k", "type"},
+	balance:     []string{"saldo", "balance"},
+	reference:   []string{"no jurnal", "journal no", "no referensi"},
+}
+
+func (bniImporter) Name() string { return "bni" }
+
+func (bniImporter) Detect(data []byte) bool {
+	head := strings.ToLower(string(data[:min(len(data), 4096)]))
+	return strings.Contains(head, "uraian transaksi") && !strings.Contains(head, "teller") && hasHeader(data, bniColumns)
+}
+
+func (bniImporter) Parse(data []byte) (*Statement, error) {
+	records, lines, err := readCSVLines(data, DetectDelimiter(data))
+	if err != nil {
+		return nil, err
+	}
+
+	headerRow, idx, ok := locateBankHeader(records, bniColumns)
+	if !ok {
+		return nil, errors.New("BNI statement header not found")
+	}
+
+	stmt := &Statement{Currency: "IDR"}
+	for _, rec := range records[:headerRow] {
+		if strings.HasPrefix(normalizeHeader(column(rec, 0)), "no rekening") {
+			stmt.AccountNumber = stripQuote(column(rec, 1))
+		}
+	}
+
+	parseBankTable(records, lines, headerRow, idx, bankTableOptions{bank: "bni"}, stmt)
+	return stmt, nil
+}
@@ -0,0 +1,59 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+)
+
+// briImporter membaca ekspor mutasi BRI. Dua varian didukung:
+//
+// Internet Banking BRI / BRImo (kolom dengan underscore):
+//
+//	TGL_TRAN,DESK_TRAN,MUTASI_DEBET,MUTASI_KREDIT,SALDO_AKHIR_MUTASI
+//	01/10/24 08:15:00,TRANSFER KE BUDI,150000.00,0.00,1850000.00
+//
+// E-statement BRI:
+//
+//	Tanggal Transaksi,Uraian Transaksi,Teller,Debet,Kredit,Saldo
+//	01/10/2024 08:15:00,TRANSFER KE BUDI,8888,"150.000,00","0,00","1.850.000,00"
+type briImporter struct{}
+
+var briColumns = bankColumns{
+	date:        []string{"tgl tran", "tanggal transaksi", "tanggal", "tgl transaksi"},
+	description: []string{"desk tran", "uraian transaksi", "keterangan"},
+	debit:       []string{"mutasi debet", "debet", "debit"},
+	credit:      []string{"mutasi kredit", "kredit", "credit"},
+	balance:     []string{"saldo akhir mutasi", "saldo"},
+	reference:   []string{"no referensi", "ref num", "reference"},
+}
+
+func (briImporter) Name() string { return "bri" }
+
+func (briImporter) Detect(data []byte) bool {
+	head := strings.ToLower(string(data[:min(len(data), 4096)]))
+	brimo := strings.Contains(head, "tgl_tran") && strings.Contains(head, "desk_tran")
+	estatement := strings.Contains(head, "uraian transaksi") && strings.Contains(head, "teller")
+	return (brimo || estatement) && hasHeader(data, briColumns)
+}
+
+func (briImporter) Parse(data []byte) (*Statement, error) {
+	records, lines, err := readCSVLines(data, DetectDelimiter(data))
+	if err != nil {
+		return nil, err
+	}
+
+	headerRow, idx, ok := locateBankHeader(records, briColumns)
+	if !ok {
+		return nil, errors.New("BRI statement header not found")
+	}
+
+	stmt := &Statement{Currency: "IDR"}
+	for _, rec := range records[:headerRow] {
+		if strings.HasPrefix(normalizeHeader(column(rec, 0)), "no rekening") {
+			stmt.AccountNumber = stripQuote(column(rec, 1))
+		}
+	}
+
+	parseBankTable(records, lines, headerRow, idx, bankTableOptions{bank: "bri"}, stmt)
+	return stmt, nil
+}
@@ -0,0 +1,232 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// camtImporter membaca rekening koran ISO 20022 CAMT.053 (BkToCstmrStmt) yang
+// disediakan bank untuk rekening bisnis. Tag XML dicocokkan tanpa namespace sehingga
+// versi camt.053.001.02 sampai .001.08 bisa dibaca oleh struct yang sama.
+type camtImporter struct{}
+
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	ID      string        `xml:"Id"`
+	IBAN    string        `xml:"Acct>Id>IBAN"`
+	OtherID string        `xml:"Acct>Id>Othr>Id"`
+	Ccy     string        `xml:"Acct>Ccy"`
+	Balance []camtBalance `xml:"Bal"`
+	Entries []camtEntry   `xml:"Ntry"`
+}
+
+type camtAmount struct {
+	Value string `xml:",chardata"`
+	Ccy   string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+// camtStatus menampung <Sts>BOOK</Sts> (camt.053.001.02 - .001.07)
+// maupun <Sts><Cd>BOOK</Cd></Sts> (camt.053.001.08 ke atas)
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtBalance struct {
+	Code      string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    camtAmount `xml:"Amt"`
+	CdtDbtInd string     `xml:"CdtDbtInd"`
+	Date      camtDate   `xml:"Dt"`
+}
+
+type camtEntry struct {
+	Amount      camtAmount `xml:"Amt"`
+	CdtDbtInd   string     `xml:"CdtDbtInd"`
+	Reversal    bool       `xml:"RvslInd"`
+	Status      camtStatus `xml:"Sts"`
+	BookingDate camtDate   `xml:"BookgDt"`
+	ValueDate   camtDate   `xml:"ValDt"`
+	EntryRef    string     `xml:"NtryRef"`
+	ServicerRef string     `xml:"AcctSvcrRef"`
+	Info        string     `xml:"AddtlNtryInf"`
+	Details     []camtTx   `xml:"NtryDtls>TxDtls"`
+}
+
+type camtTx struct {
+	ServicerRef  string   `xml:"Refs>AcctSvcrRef"`
+	EndToEndID   string   `xml:"Refs>EndToEndId"`
+	Creditor     string   `xml:"RltdPties>Cdtr>Nm"`
+	CreditorPty  string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+	Debtor       string   `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPty    string   `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Unstructured []string `xml:"RmtInf>Ustrd"`
+	Info         string   `xml:"AddtlTxInf"`
+}
+
+func (camtImporter) Name() string { return "camt053" }
+
+func (camtImporter) Detect(data []byte) bool {
+	head := data[:min(len(data), 4096)]
+	return bytes.Contains(head, []byte("BkToCstmrStmt")) || bytes.Contains(head, []byte("camt.053"))
+}
+
+func (camtImporter) Parse(data []byte) (*Statement, error) {
+	var doc camtDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid CAMT.053 XML: %w", err)
+	}
+	if len(doc.Statements) == 0 {
+		return nil, errors.New("no <Stmt> found in CAMT.053 file")
+	}
+
+	stmt := &Statement{}
+	for _, s := range doc.Statements {
+		if stmt.AccountNumber == "" {
+			stmt.AccountNumber = s.IBAN
+			if stmt.AccountNumber == "" {
+				stmt.AccountNumber = s.OtherID
+			}
+		}
+		if stmt.Currency == "" {
+			stmt.Currency = s.Ccy
+		}
+
+		// Saldo penutup yang sudah dibukukan (CLBD) dari statement terakhir
+		for _, bal := range s.Balance {
+			if bal.Code != "CLBD" {
+				continue
+			}
+			amount, err := ParseAmount(bal.Amount.Value, DecimalDot)
+			if err != nil {
+				continue
+			}
+			if bal.CdtDbtInd == "DBIT" {
+				amount = -amount
+			}
+			stmt.LedgerBalance = &amount
+			stmt.LedgerDate, _ = bal.Date.parse()
+			if stmt.Currency == "" {
+				stmt.Currency = bal.Amount.Ccy
+			}
+		}
+
+		for _, entry := range s.Entries {
+			stmt.Rows = append(stmt.Rows, entry.row())
+		}
+	}
+
+	for i := range stmt.Rows {
+		stmt.Rows[i].Line = i + 1 // Nomor entri, XML tidak punya baris yang berarti
+	}
+	return stmt, nil
+}
+
+func (d camtDate) parse() (time.Time, error) {
+	if d.Date != "" {
+		return time.Parse("2006-01-02", strings.TrimSpace(d.Date))
+	}
+	if len(d.DateTime) >= 10 {
+		return time.Parse("2006-01-02", d.DateTime[:10])
+	}
+	return time.Time{}, errors.New("missing date")
+}
+
+func (e camtEntry) row() models.ImportRow {
+	row := models.ImportRow{Category: DefaultCategory}
+
+	var descParts []string
+	addPart := func(s string) {
+		s = strings.Join(strings.Fields(s), " ")
+		if s == "" {
+			return
+		}
+		for _, p := range descParts {
+			if p == s {
+				return
+			}
+		}
+		descParts = append(descParts, s)
+	}
+
+	if e.Reversal {
+		addPart("Reversal")
+	}
+	// Pihak lawan diambil dari arah transaksi asli; TxDtls pembatalan tetap memuat pihak
+	// transaksi yang dibatalkan
+	for _, tx := range e.Details {
+		if (e.CdtDbtInd == "DBIT") != e.Reversal {
+			addPart(tx.Creditor)
+			addPart(tx.CreditorPty)
+		} else {
+			addPart(tx.Debtor)
+			addPart(tx.DebtorPty)
+		}
+		for _, u := range tx.Unstructured {
+			addPart(u)
+		}
+		addPart(tx.Info)
+
+		if row.Reference == "" {
+			row.Reference = tx.ServicerRef
+		}
+		if row.Reference == "" && tx.EndToEndID != "NOTPROVIDED" {
+			row.Reference = tx.EndToEndID
+		}
+	}
+	addPart(e.Info)
+	row.Description = strings.Join(descParts, " - ")
+
+	// Referensi level entri lebih diutamakan karena satu entri bisa berisi banyak TxDtls
+	if e.ServicerRef != "" {
+		row.Reference = e.ServicerRef
+	} else if row.Reference == "" {
+		row.Reference = e.EntryRef
+	}
+
+	date, err := e.BookingDate.parse()
+	if err != nil {
+		date, err = e.ValueDate.parse()
+	}
+	if err != nil {
+		row.Error = "entry has no booking or value date"
+		return row
+	}
+	row.Date = date
+
+	amount, err := ParseAmount(e.Amount.Value, DecimalDot)
+	if err != nil || amount <= 0 {
+		row.Error = fmt.Sprintf("invalid amount %q", e.Amount.Value)
+		return row
+	}
+	row.Amount = amount
+
+	// CdtDbtInd entri pembatalan sudah menunjukkan arah uang yang sebenarnya
+	// (pembatalan debit = CRDT), jadi dipakai apa adanya
+	row.Type = "income"
+	if e.CdtDbtInd == "DBIT" {
+		row.Type = "expense"
+	}
+
+	status := strings.TrimSpace(e.Status.Code)
+	if status == "" {
+		status = strings.TrimSpace(e.Status.Value)
+	}
+	if status == "PDNG" {
+		row.Skipped = "pending entry (PDNG), import it again once it is booked"
+	}
+	return row
+}
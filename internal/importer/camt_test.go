@@ -0,0 +1,43 @@
+package importer
+
+import "testing"
+
+func TestCAMTReversal(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+<BkToCstmrStmt><Stmt>
+<Acct><Id><Othr><Id>0001112223</Id></Othr></Id><Ccy>IDR</Ccy></Acct>
+<Ntry>
+  <Amt Ccy="IDR">150000.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts>
+  <BookgDt><Dt>2024-10-01</Dt></BookgDt><AcctSvcrRef>REF-1</AcctSvcrRef>
+  <NtryDtls><TxDtls><RltdPties><Cdtr><Nm>TOKO CONTOH</Nm></Cdtr></RltdPties></TxDtls></NtryDtls>
+</Ntry>
+<Ntry>
+  <Amt Ccy="IDR">150000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><RvslInd>true</RvslInd><Sts>BOOK</Sts>
+  <BookgDt><Dt>2024-10-02</Dt></BookgDt><AcctSvcrRef>REF-2</AcctSvcrRef>
+  <NtryDtls><TxDtls><RltdPties><Cdtr><Nm>TOKO CONTOH</Nm></Cdtr></RltdPties></TxDtls></NtryDtls>
+</Ntry>
+</Stmt></BkToCstmrStmt>
+</Document>`)
+
+	stmt, err := camtImporter{}.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		typ         string
+		description string
+	}{
+		{"expense", "TOKO CONTOH"},
+		{"income", "Reversal - TOKO CONTOH"}, // Pembatalan debit: uang kembali masuk
+	}
+	if len(stmt.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(stmt.Rows), len(want))
+	}
+	for i, w := range want {
+		got := stmt.Rows[i]
+		if got.Type != w.typ || got.Description != w.description || got.Amount != 15000000 {
+			t.Errorf("row %d = %s %q %d, want %s %q 15000000", i, got.Type, got.Description, got.Amount, w.typ, w.description)
+		}
+	}
+}
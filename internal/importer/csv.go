@@ -0,0 +1,435 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// Nama field yang dipakai di models.CSVMapping.Columns
+const (
+	FieldDate               = "date"
+	FieldAmount             = "amount"
+	FieldDebit              = "debit"
+	FieldCredit             = "credit"
+	FieldType               = "type"
+	FieldCategory           = "category"
+	FieldDescription        = "description"
+	FieldAccount            = "account"
+	FieldDestinationAccount = "destination_account"
+)
+
+// sampleSize adalah jumlah baris yang dipakai untuk deteksi otomatis
+const sampleSize = 50
+
+// headerSynonyms memetakan field ke nama kolom yang umum dipakai.
+// Urutan penting: "akun tujuan" harus dicek sebelum "akun".
+var headerSynonyms = []struct {
+	field string
+	names []string
+}{
+	{FieldDestinationAccount, []string{"akun tujuan", "destination account", "to account", "destination"}},
+	{FieldAccount, []string{"akun asal", "akun", "account", "rekening", "from account"}},
+	{FieldDate, []string{"tanggal", "tgl", "date", "transaction date", "tanggal transaksi"}},
+	{FieldDebit, []string{"debit", "debet", "keluar", "withdrawal", "pengeluaran"}},
+	{FieldCredit, []string{"kredit", "credit", "masuk", "deposit", "pemasukan"}},
+	{FieldAmount, []string{"jumlah", "amount", "nominal", "jumlah rp", "mutasi", "value"}},
+	{FieldType, []string{"tipe", "type", "jenis"}},
+	{FieldCategory, []string{"kategori", "category"}},
+	{FieldDescription, []string{"deskripsi", "description", "keterangan", "memo", "catatan", "note", "uraian"}},
+}
+
+var candidateDelimiters = []rune{',', ';', '\t', '|'}
+
+// ReadCSV membaca seluruh isi file CSV dengan delimiter tertentu.
+// BOM UTF-8 (dari Excel) dibuang dan jumlah kolom per baris boleh berbeda.
+func ReadCSV(data []byte, delimiter rune) ([][]string, error) {
+	records, _, err := readCSVLines(data, delimiter)
+	return records, err
+}
+
+// readCSVLines sama seperti ReadCSV, ditambah nomor baris file (mulai dari 1) untuk setiap
+// record. encoding/csv melewati baris kosong, jadi indeks record tidak sama dengan nomor baris.
+func readCSVLines(data []byte, delimiter rune) ([][]string, []int, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	var records [][]string
+	var lines []int
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, rec)
+		lines = append(lines, line)
+	}
+	if len(records) == 0 {
+		return nil, nil, errors.New("file is empty")
+	}
+	return records, lines, nil
+}
+
+// DetectDelimiter memilih delimiter yang menghasilkan jumlah kolom paling
+// konsisten (dan lebih dari satu) pada baris-baris awal file.
+func DetectDelimiter(data []byte) rune {
+	best, bestScore := ',', 0
+	for _, d := range candidateDelimiters {
+		records, err := ReadCSV(data, d)
+		if err != nil {
+			continue
+		}
+		if len(records) > sampleSize {
+			records = records[:sampleSize]
+		}
+
+		counts := make(map[int]int)
+		for _, rec := range records {
+			counts[len(rec)]++
+		}
+		for fields, n := range counts {
+			if fields < 2 {
+				continue
+			}
+			// Baris dengan jumlah kolom yang sama, diberi bobot jumlah kolomnya
+			if score := n*100 + fields; score > bestScore {
+				best, bestScore = d, score
+			}
+		}
+	}
+	return best
+}
+
+// ParseDelimiter mengubah delimiter dari JSON ("," ";" "\t" "tab" "|") menjadi rune
+func ParseDelimiter(s string) (rune, error) {
+	switch s {
+	case "", ",":
+		return ',', nil
+	case ";":
+		return ';', nil
+	case "\t", "\\t", "tab":
+		return '\t', nil
+	case "|":
+		return '|', nil
+	}
+	return 0, fmt.Errorf("unsupported delimiter %q", s)
+}
+
+func normalizeHeader(h string) string {
+	h = strings.ToLower(h)
+	h = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, h)
+	return strings.Join(strings.Fields(h), " ")
+}
+
+// column mengembalikan nilai kolom ke-i, atau "" jika baris terlalu pendek
+func column(rec []string, i int) string {
+	if i < 0 || i >= len(rec) {
+		return ""
+	}
+	return strings.TrimSpace(rec[i])
+}
+
+func columnValues(records [][]string, i int) []string {
+	values := make([]string, 0, len(records))
+	for _, rec := range records {
+		if v := column(rec, i); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// matchHeaders mencocokkan nama kolom dengan headerSynonyms
+func matchHeaders(header []string) map[string]int {
+	columns := make(map[string]int)
+	used := make(map[int]bool)
+
+	// Putaran pertama: nama persis sama. Putaran kedua: nama mengandung sinonim.
+	for _, exact := range []bool{true, false} {
+		for _, syn := range headerSynonyms {
+			if _, ok := columns[syn.field]; ok {
+				continue
+			}
+			for i, h := range header {
+				if used[i] {
+					continue
+				}
+				name := normalizeHeader(h)
+				matched := false
+				for _, candidate := range syn.names {
+					if (exact && name == candidate) || (!exact && strings.Contains(name, candidate)) {
+						matched = true
+						break
+					}
+				}
+				if matched {
+					columns[syn.field] = i
+					used[i] = true
+					break
+				}
+			}
+		}
+	}
+	return columns
+}
+
+// inferColumns menebak kolom dari isinya, untuk file tanpa baris header
+func inferColumns(records [][]string) map[string]int {
+	columns := make(map[string]int)
+	width := 0
+	for _, rec := range records {
+		if len(rec) > width {
+			width = len(rec)
+		}
+	}
+
+	longestText, longestIdx := 0, -1
+	for i := 0; i < width; i++ {
+		values := columnValues(records, i)
+		if len(values) == 0 {
+			continue
+		}
+		if _, ok := columns[FieldDate]; !ok && DetectDateFormat(values) != "" {
+			columns[FieldDate] = i
+			continue
+		}
+		if _, ok := columns[FieldAmount]; !ok && allAmounts(values) {
+			columns[FieldAmount] = i
+			continue
+		}
+		total := 0
+		for _, v := range values {
+			total += len(v)
+		}
+		if avg := total / len(values); avg > longestText {
+			longestText, longestIdx = avg, i
+		}
+	}
+	if longestIdx >= 0 {
+		columns[FieldDescription] = longestIdx
+	}
+	return columns
+}
+
+func allAmounts(values []string) bool {
+	style := DetectDecimalStyle(values)
+	for _, v := range values {
+		if _, err := ParseAmount(v, style); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// looksLikeHeader bernilai true jika tidak ada sel di baris pertama yang berupa tanggal atau nominal
+func looksLikeHeader(rec []string) bool {
+	for _, cell := range rec {
+		cell = strings.TrimSpace(cell)
+		if cell == "" {
+			continue
+		}
+		if DetectDateFormat([]string{cell}) != "" {
+			return false
+		}
+		if _, err := ParseAmount(cell, DecimalDot); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// SuggestMapping membaca file dan menebak delimiter, header, format tanggal,
+// gaya desimal, serta kolom mana yang berisi tanggal, nominal, dst.
+func SuggestMapping(data []byte) (models.CSVMapping, [][]string, error) {
+	delimiter := DetectDelimiter(data)
+	records, err := ReadCSV(data, delimiter)
+	if err != nil {
+		return models.CSVMapping{}, nil, err
+	}
+
+	mapping := models.CSVMapping{
+		Delimiter: string(delimiter),
+		HasHeader: looksLikeHeader(records[0]),
+	}
+
+	body := records
+	if mapping.HasHeader {
+		body = records[1:]
+		mapping.Columns = matchHeaders(records[0])
+	}
+	sample := body
+	if len(sample) > sampleSize {
+		sample = sample[:sampleSize]
+	}
+	if len(mapping.Columns) == 0 {
+		mapping.Columns = inferColumns(sample)
+	}
+
+	if i, ok := mapping.Columns[FieldDate]; ok {
+		mapping.DateFormat = DetectDateFormat(columnValues(sample, i))
+	}
+	if mapping.DateFormat == "" {
+		mapping.DateFormat = DateFormats[0]
+	}
+
+	var amountValues []string
+	for _, field := range []string{FieldAmount, FieldDebit, FieldCredit} {
+		if i, ok := mapping.Columns[field]; ok {
+			amountValues = append(amountValues, columnValues(sample, i)...)
+		}
+	}
+	mapping.DecimalStyle = DetectDecimalStyle(amountValues)
+
+	return mapping, records, nil
+}
+
+// ValidateMapping memastikan mapping punya kolom minimum yang dibutuhkan
+func ValidateMapping(m models.CSVMapping) error {
+	if _, ok := m.Columns[FieldDate]; !ok {
+		return errors.New("mapping must include a date column")
+	}
+	_, hasAmount := m.Columns[FieldAmount]
+	_, hasDebit := m.Columns[FieldDebit]
+	_, hasCredit := m.Columns[FieldCredit]
+	if !hasAmount && !hasDebit && !hasCredit {
+		return errors.New("mapping must include an amount column or debit/credit columns")
+	}
+	if m.DateFormat == "" {
+		return errors.New("mapping must include a date format")
+	}
+	if m.DecimalStyle != DecimalDot && m.DecimalStyle != DecimalComma {
+		return errors.New("decimal style must be 'dot' or 'comma'")
+	}
+	return nil
+}
+
+// ParseCSV mengubah baris-baris CSV menjadi ImportRow sesuai mapping.
+// Baris yang gagal diparse tetap dikembalikan dengan field Error terisi.
+func ParseCSV(records [][]string, m models.CSVMapping) []models.ImportRow {
+	start := 0
+	if m.HasHeader {
+		start = 1
+	}
+
+	colIdx := func(field string) int {
+		if i, ok := m.Columns[field]; ok {
+			return i
+		}
+		return -1
+	}
+
+	rows := make([]models.ImportRow, 0, len(records)-start)
+	for n := start; n < len(records); n++ {
+		rec := records[n]
+		if strings.TrimSpace(strings.Join(rec, "")) == "" {
+			continue // Lewati baris kosong
+		}
+
+		row := models.ImportRow{
+			Line:                   n + 1,
+			Category:               column(rec, colIdx(FieldCategory)),
+			Description:            column(rec, colIdx(FieldDescription)),
+			AccountName:            column(rec, colIdx(FieldAccount)),
+			DestinationAccountName: column(rec, colIdx(FieldDestinationAccount)),
+		}
+		if err := parseCSVRow(&row, rec, m, colIdx); err != nil {
+			row.Error = err.Error()
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func parseCSVRow(row *models.ImportRow, rec []string, m models.CSVMapping, colIdx func(string) int) error {
+	// 1. Tanggal
+	dateStr := column(rec, colIdx(FieldDate))
+	if dateStr == "" {
+		return errors.New("missing date")
+	}
+	date, err := time.Parse(m.DateFormat, dateStr)
+	if err != nil {
+		return fmt.Errorf("invalid date %q, expected format %s", dateStr, m.DateFormat)
+	}
+	row.Date = date
+
+	// 2. Nominal: satu kolom bertanda (+/-) atau kolom debit/kredit terpisah
+	var amount int64
+	if i := colIdx(FieldAmount); i >= 0 {
+		amount, err = ParseAmount(column(rec, i), m.DecimalStyle)
+		if err != nil {
+			return fmt.Errorf("invalid amount %q", column(rec, i))
+		}
+	} else {
+		debitStr, creditStr := column(rec, colIdx(FieldDebit)), column(rec, colIdx(FieldCredit))
+		if debitStr != "" {
+			debit, err := ParseAmount(debitStr, m.DecimalStyle)
+			if err != nil {
+				return fmt.Errorf("invalid debit amount %q", debitStr)
+			}
+			if debit < 0 {
+				debit = -debit
+			}
+			amount -= debit
+		}
+		if creditStr != "" {
+			credit, err := ParseAmount(creditStr, m.DecimalStyle)
+			if err != nil {
+				return fmt.Errorf("invalid credit amount %q", creditStr)
+			}
+			if credit < 0 {
+				credit = -credit
+			}
+			amount += credit
+		}
+	}
+	if amount == 0 {
+		return errors.New("amount cannot be zero")
+	}
+
+	// 3. Tipe: dari kolom tipe jika ada, jika tidak dari tanda nominal
+	if t, ok := ParseType(column(rec, colIdx(FieldType))); ok {
+		row.Type = t
+	} else if amount < 0 {
+		row.Type = "expense"
+	} else {
+		row.Type = "income"
+	}
+	if amount < 0 {
+		amount = -amount
+	}
+	row.Amount = amount
+
+	// 4. Kategori default
+	if row.Type == "transfer" {
+		if row.DestinationAccountName == "" {
+			return errors.New("transfer requires a destination account")
+		}
+		if row.Category == "" {
+			row.Category = TransferCategory
+		}
+	} else if row.Category == "" {
+		row.Category = DefaultCategory
+	}
+	return nil
+}
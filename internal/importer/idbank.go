@@ -0,0 +1,377 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// Helper bersama untuk parser mutasi bank Indonesia (BCA, Mandiri, BNI, BRI).
+
+var indonesianMonths = map[string]time.Month{
+	"jan": time.January, "januari": time.January, "january": time.January,
+	"feb": time.February, "peb": time.February, "februari": time.February, "pebruari": time.February, "february": time.February,
+	"mar": time.March, "maret": time.March, "march": time.March,
+	"apr": time.April, "april": time.April,
+	"mei": time.May, "may": time.May,
+	"jun": time.June, "juni": time.June, "june": time.June,
+	"jul": time.July, "juli": time.July, "july": time.July,
+	"agu": time.August, "ags": time.August, "agt": time.August, "agus": time.August, "agustus": time.August, "aug": time.August, "august": time.August,
+	"sep": time.September, "sept": time.September, "september": time.September,
+	"okt": time.October, "oktober": time.October, "oct": time.October, "october": time.October,
+	"nov": time.November, "nop": time.November, "nopember": time.November, "november": time.November,
+	"des": time.December, "desember": time.December, "dec": time.December, "december": time.December,
+}
+
+// "01 Okt 2024", "1-Oktober-24", "01/Okt/2024 10:11:12"
+var namedMonthDate = regexp.MustCompile(`^(\d{1,2})[\s\-/.]+([A-Za-z]+)[\s\-/.]+(\d{2,4})`)
+
+var numericDateLayouts = []string{"02/01/2006", "2/1/2006", "02/01/06", "2006-01-02", "02-01-2006", "02-01-06", "2006/01/02"}
+
+// stripQuote membuang tanda kutip tunggal di depan nilai. BCA menambahkannya
+// agar Excel tidak mengubah "01/10" atau nomor rekening menjadi angka.
+func stripQuote(s string) string {
+	return strings.TrimPrefix(strings.TrimSpace(s), "'")
+}
+
+// ParseIndonesianDate mem-parsing tanggal dengan nama bulan Indonesia maupun format angka
+// (hari/bulan/tahun). Jam di belakang tanggal diabaikan. Jika tanggal tidak menyertakan
+// tahun ("01/10" di mutasi BCA), defaultYear yang dipakai.
+func ParseIndonesianDate(raw string, defaultYear int) (time.Time, error) {
+	s := stripQuote(raw)
+
+	if m := namedMonthDate.FindStringSubmatch(s); m != nil {
+		month, ok := indonesianMonths[strings.ToLower(m[2])]
+		if !ok {
+			return time.Time{}, fmt.Errorf("unknown month %q", m[2])
+		}
+		day, _ := strconv.Atoi(m[1])
+		year, _ := strconv.Atoi(m[3])
+		if year < 100 {
+			year += 2000
+		}
+		return validDate(year, month, day)
+	}
+
+	datePart, _, _ := strings.Cut(s, " ")
+	for _, layout := range numericDateLayouts {
+		if t, err := time.Parse(layout, datePart); err == nil {
+			return t, nil
+		}
+	}
+
+	// Tanggal tanpa tahun, misal "01/10"
+	if defaultYear > 0 {
+		if t, err := time.Parse("02/01", datePart); err == nil {
+			return validDate(defaultYear, t.Month(), t.Day())
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", raw)
+}
+
+func validDate(year int, month time.Month, day int) (time.Time, error) {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if t.Day() != day || t.Month() != month {
+		return time.Time{}, fmt.Errorf("invalid date %d-%02d-%02d", year, month, day)
+	}
+	return t, nil
+}
+
+// parseDebitCredit mengenali penanda mutasi: DB/D/Debet untuk uang keluar,
+// CR/K/C/Kredit untuk uang masuk.
+func parseDebitCredit(marker string) (string, bool) {
+	switch strings.ToUpper(strings.TrimSpace(marker)) {
+	case "DB", "D", "DR", "DEBET", "DEBIT":
+		return "expense", true
+	case "CR", "K", "C", "KR", "KREDIT", "CREDIT":
+		return "income", true
+	}
+	return "", false
+}
+
+// splitAmountMarker memisahkan nominal dan penanda di sel yang sama, misal "150,000.00 DB"
+func splitAmountMarker(cell string) (string, string) {
+	cell = strings.TrimSpace(cell)
+	if i := strings.LastIndexAny(cell, " \t"); i >= 0 {
+		if _, ok := parseDebitCredit(cell[i+1:]); ok {
+			return strings.TrimSpace(cell[:i]), cell[i+1:]
+		}
+	}
+	return cell, ""
+}
+
+// isPending bernilai true untuk transaksi yang belum dibukukan (ditandai "PEND")
+func isPending(cell string) bool {
+	return strings.EqualFold(stripQuote(cell), "PEND")
+}
+
+// bankColumns berisi sinonim nama header untuk setiap kolom mutasi bank
+type bankColumns struct {
+	date        []string
+	description []string // Boleh cocok ke beberapa kolom, hasilnya digabung
+	amount      []string // Nominal tanpa tanda, tipe dari kolom marker
+	marker      []string // Kolom DB/CR
+	debit       []string
+	credit      []string
+	balance     []string
+	reference   []string
+}
+
+// bankColumnIndex adalah hasil pencocokan header, -1 berarti kolom tidak ada
+type bankColumnIndex struct {
+	date, amount, marker, debit, credit, balance, reference int
+	description                                             []int
+}
+
+func matchesAny(name string, candidates []string) bool {
+	for _, c := range candidates {
+		if name == c {
+			return true
+		}
+	}
+	return false
+}
+
+// locateBankHeader mencari baris header pertama yang punya kolom tanggal dan nominal
+// (atau debit/kredit), lalu mengembalikan posisi baris beserta indeks kolomnya.
+func locateBankHeader(records [][]string, spec bankColumns) (int, bankColumnIndex, bool) {
+	for n, rec := range records {
+		idx := bankColumnIndex{date: -1, amount: -1, marker: -1, debit: -1, credit: -1, balance: -1, reference: -1}
+		for i, cell := range rec {
+			name := normalizeHeader(stripQuote(cell))
+			switch {
+			case idx.date < 0 && matchesAny(name, spec.date):
+				idx.date = i
+			case matchesAny(name, spec.description):
+				idx.description = append(idx.description, i)
+			case idx.amount < 0 && matchesAny(name, spec.amount):
+				idx.amount = i
+			case idx.marker < 0 && matchesAny(name, spec.marker):
+				idx.marker = i
+			case idx.debit < 0 && matchesAny(name, spec.debit):
+				idx.debit = i
+			case idx.credit < 0 && matchesAny(name, spec.credit):
+				idx.credit = i
+			case idx.balance < 0 && matchesAny(name, spec.balance):
+				idx.balance = i
+			case idx.reference < 0 && matchesAny(name, spec.reference):
+				idx.reference = i
+			}
+		}
+		if idx.date >= 0 && (idx.amount >= 0 || (idx.debit >= 0 && idx.credit >= 0)) {
+			return n, idx, true
+		}
+	}
+	return 0, bankColumnIndex{}, false
+}
+
+// hasHeader bernilai true jika ada baris yang cocok dengan spec, dipakai untuk Detect
+func hasHeader(data []byte, spec bankColumns) bool {
+	records, err := ReadCSV(data, DetectDelimiter(data))
+	if err != nil {
+		return false
+	}
+	if len(records) > sampleSize {
+		records = records[:sampleSize]
+	}
+	_, _, ok := locateBankHeader(records, spec)
+	return ok
+}
+
+// summaryRow mengenali baris ringkasan di bawah tabel mutasi
+func summaryRow(rec []string) (label string, ok bool) {
+	first := normalizeHeader(stripQuote(column(rec, 0)))
+	for _, prefix := range []string{"saldo awal", "saldo akhir", "mutasi kredit", "mutasi debet", "mutasi debit", "total", "opening balance", "closing balance"} {
+		if strings.HasPrefix(first, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// lastAmount mengambil nilai nominal terakhir yang valid di sebuah baris
+func lastAmount(rec []string, style string) (int64, bool) {
+	for i := len(rec) - 1; i >= 0; i-- {
+		v := stripQuote(rec[i])
+		if v == "" || v == ":" {
+			continue
+		}
+		if amount, err := ParseAmount(v, style); err == nil {
+			return amount, true
+		}
+	}
+	return 0, false
+}
+
+// bankTableOptions mengatur perbedaan kecil antar bank
+type bankTableOptions struct {
+	bank        string
+	defaultYear func(month time.Month) int // Untuk tanggal tanpa tahun (BCA)
+}
+
+// parseBankTable mem-parsing tabel mutasi di bawah baris header. lines adalah nomor baris file
+// setiap record (dari readCSVLines). Saldo akhir statement diambil dari baris ringkasan
+// ("Saldo Akhir"), atau dari saldo berjalan transaksi terakhir.
+func parseBankTable(records [][]string, lines []int, headerRow int, idx bankColumnIndex, opts bankTableOptions, stmt *Statement) {
+	body := records[headerRow+1:]
+
+	// Gaya desimal ditentukan dari seluruh kolom nominal & saldo
+	var amountValues []string
+	for _, i := range []int{idx.amount, idx.debit, idx.credit, idx.balance} {
+		if i >= 0 {
+			for _, rec := range body {
+				v, _ := splitAmountMarker(stripQuote(column(rec, i)))
+				if v != "" {
+					amountValues = append(amountValues, v)
+				}
+			}
+		}
+	}
+	style := DetectDecimalStyle(amountValues)
+
+	type runningBalance struct {
+		date    time.Time
+		balance int64
+	}
+	var balances []runningBalance
+
+	for n, rec := range body {
+		if strings.TrimSpace(strings.Join(rec, "")) == "" {
+			continue
+		}
+		if label, ok := summaryRow(rec); ok {
+			if label == "saldo akhir" || label == "closing balance" {
+				if amount, ok := lastAmount(rec[1:], style); ok {
+					stmt.LedgerBalance = &amount
+				}
+			}
+			continue
+		}
+
+		row := models.ImportRow{Line: lines[headerRow+n+1], Category: DefaultCategory}
+		var descParts []string
+		for _, i := range idx.description {
+			if v := stripQuote(column(rec, i)); v != "" {
+				descParts = append(descParts, strings.Join(strings.Fields(v), " "))
+			}
+		}
+		row.Description = strings.Join(descParts, " ")
+
+		if err := parseBankRow(&row, rec, idx, opts, style); err != nil {
+			row.Error = err.Error()
+		}
+		stmt.Rows = append(stmt.Rows, row)
+
+		if row.Error == "" && row.Skipped == "" && idx.balance >= 0 {
+			if balance, err := ParseAmount(stripQuote(column(rec, idx.balance)), style); err == nil {
+				balances = append(balances, runningBalance{row.Date, balance})
+			}
+		}
+	}
+
+	if len(balances) > 0 {
+		// Sebagian bank mengurutkan mutasi dari yang terbaru
+		latest := balances[len(balances)-1]
+		if balances[0].date.After(latest.date) {
+			latest = balances[0]
+		}
+		if stmt.LedgerBalance == nil {
+			stmt.LedgerBalance = &latest.balance
+		}
+		if stmt.LedgerDate.IsZero() {
+			stmt.LedgerDate = latest.date
+		}
+	}
+}
+
+func parseBankRow(row *models.ImportRow, rec []string, idx bankColumnIndex, opts bankTableOptions, style string) error {
+	dateCell := column(rec, idx.date)
+	pending := isPending(dateCell)
+	if !pending {
+		year := 0
+		if opts.defaultYear != nil {
+			// Tebak bulan dulu tanpa tahun untuk menentukan tahun yang tepat
+			if t, err := time.Parse("02/01", stripQuote(dateCell)); err == nil {
+				year = opts.defaultYear(t.Month())
+			}
+		}
+		date, err := ParseIndonesianDate(dateCell, year)
+		if err != nil {
+			return err
+		}
+		row.Date = date
+	}
+
+	var amount int64
+	if idx.amount >= 0 {
+		value, marker := splitAmountMarker(stripQuote(column(rec, idx.amount)))
+		if idx.marker >= 0 && marker == "" {
+			marker = column(rec, idx.marker)
+		}
+		parsed, err := ParseAmount(value, style)
+		if err != nil {
+			return fmt.Errorf("invalid amount %q", value)
+		}
+		if t, ok := parseDebitCredit(marker); ok {
+			if parsed < 0 {
+				parsed = -parsed
+			}
+			if t == "expense" {
+				parsed = -parsed
+			}
+		}
+		amount = parsed
+	} else {
+		for _, col := range []struct {
+			i    int
+			sign int64
+		}{{idx.debit, -1}, {idx.credit, 1}} {
+			v := stripQuote(column(rec, col.i))
+			if v == "" {
+				continue
+			}
+			parsed, err := ParseAmount(v, style)
+			if err != nil {
+				return fmt.Errorf("invalid amount %q", v)
+			}
+			if parsed < 0 {
+				parsed = -parsed
+			}
+			amount += col.sign * parsed
+		}
+	}
+	if amount == 0 {
+		return errors.New("amount cannot be zero")
+	}
+
+	row.Type = "income"
+	if amount < 0 {
+		row.Type = "expense"
+		amount = -amount
+	}
+	row.Amount = amount
+
+	if pending {
+		row.Skipped = "pending transaction (PEND), import it again once it is posted"
+		return nil
+	}
+
+	// Referensi: dari kolom referensi bank jika ada, jika tidak dari tanggal + nominal +
+	// saldo berjalan (kombinasi ini unik di satu rekening).
+	if idx.reference >= 0 {
+		if ref := stripQuote(column(rec, idx.reference)); ref != "" && strings.Trim(ref, "0") != "" {
+			row.Reference = fmt.Sprintf("%s:%s:%s", opts.bank, row.Date.Format("20060102"), ref)
+		}
+	}
+	if row.Reference == "" && idx.balance >= 0 {
+		if balance, err := ParseAmount(stripQuote(column(rec, idx.balance)), style); err == nil {
+			row.Reference = fmt.Sprintf("%s:%s:%s%d:%d", opts.bank, row.Date.Format("20060102"), row.Type[:1], row.Amount, balance)
+		}
+	}
+	return nil
+}
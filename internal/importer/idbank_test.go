@@ -0,0 +1,242 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type wantBankRow struct {
+	line        int
+	date        string // Kosong untuk baris PEND
+	amount      int64
+	typ         string
+	description string
+	reference   string
+	skipped     bool
+	err         bool
+}
+
+func TestBankStatements(t *testing.T) {
+	tests := []struct {
+		file          string
+		format        string
+		account       string
+		ledgerBalance int64 // -1 jika statement tidak punya saldo
+		ledgerDate    string
+		rows          []wantBankRow
+	}{
+		{
+			// Tanggal tanpa tahun, periode melewati tahun baru, kolom DB/CR terpisah,
+			// baris PEND dan baris ringkasan di bawah tabel
+			file: "bca.csv", format: "bca", account: "0001112223",
+			ledgerBalance: 434000050, ledgerDate: "2025-01-14",
+			rows: []wantBankRow{
+				{line: 7, date: "2024-12-20", amount: 15000000, typ: "expense",
+					description: "TRSF E-BANKING DB 2012/FTSCY/WS95031 PEMBAYARAN LISTRIK", reference: "bca:20241220:e15000000:185000000"},
+				{line: 8, date: "2024-12-27", amount: 250000050, typ: "income",
+					description: "BI-FAST CR TRANSFER DR 014 NASABAH LAIN", reference: "bca:20241227:i250000050:435000050"},
+				{line: 9, date: "2025-01-02", amount: 1000000, typ: "expense", description: "BIAYA ADM", reference: "bca:20250102:e1000000:434000050"},
+				{line: 10, amount: 7500000, typ: "expense", description: "KARTU DEBIT TOKO CONTOH", skipped: true},
+			},
+		},
+		{
+			// Kolom Debit/Credit gaya 1,234.56, dua kolom Description digabung, nomor referensi
+			file: "mandiri_online.csv", format: "mandiri", account: "0009998887", ledgerBalance: -1,
+			rows: []wantBankRow{
+				{line: 2, date: "2024-10-01", amount: 15000000, typ: "expense", description: "TRANSFER KE PENERIMA CONTOH", reference: "mandiri:20241001:000123"},
+				{line: 3, date: "2024-10-03", amount: 750000000, typ: "income", description: "GAJI PT CONTOH", reference: "mandiri:20241003:000456"},
+				// Referensi nol semua diabaikan dan tidak ada kolom saldo
+				{line: 4, date: "2024-10-05", amount: 1250000, typ: "expense", description: "BIAYA ADM"},
+			},
+		},
+		{
+			// Nama bulan Indonesia, nominal gaya 1.234,56, baris ringkasan Saldo Awal/Akhir
+			file: "mandiri_livin.csv", format: "mandiri",
+			ledgerBalance: 195573456, ledgerDate: "2024-10-31",
+			rows: []wantBankRow{
+				{line: 5, date: "2024-10-01", amount: 100000000, typ: "income", description: "Transfer dari PENGIRIM CONTOH", reference: "mandiri:20241001:i100000000:200000000"},
+				{line: 6, date: "2024-08-15", amount: 4550000, typ: "expense", description: "Pembayaran QRIS TOKO CONTOH", reference: "mandiri:20240815:e4550000:195450000"},
+				{line: 7, date: "2024-10-31", amount: 123456, typ: "income", description: "Bunga", reference: "mandiri:20241031:i123456:195573456"},
+			},
+		},
+		{
+			// Kolom Tipe Cr/Db/D, tanggal dengan jam, baris Total di bawah tabel
+			file: "bni.csv", format: "bni", account: "0123450000",
+			ledgerBalance: 125000000, ledgerDate: "2024-11-03",
+			rows: []wantBankRow{
+				{line: 4, date: "2024-10-01", amount: 100000000, typ: "income", description: "TRANSFER DARI PENGIRIM CONTOH", reference: "bni:20241001:i100000000:200000000"},
+				{line: 5, date: "2024-10-02", amount: 25000000, typ: "expense", description: "PEMBAYARAN PLN", reference: "bni:20241002:e25000000:175000000"},
+				{line: 6, date: "2024-11-03", amount: 50000000, typ: "expense", description: "TARIK TUNAI ATM", reference: "bni:20241103:e50000000:125000000"},
+			},
+		},
+		{
+			// Urutan terbaru dulu: saldo akhir diambil dari transaksi dengan tanggal terakhir
+			file: "bri_brimo.csv", format: "bri",
+			ledgerBalance: 235000000, ledgerDate: "2024-10-03",
+			rows: []wantBankRow{
+				{line: 2, date: "2024-10-03", amount: 50000000, typ: "income", description: "TRANSFER DARI PENGIRIM CONTOH", reference: "bri:20241003:i50000000:235000000"},
+				{line: 3, date: "2024-10-01", amount: 15000000, typ: "expense", description: "TRANSFER KE PENERIMA CONTOH", reference: "bri:20241001:e15000000:185000000"},
+			},
+		},
+		{
+			// Kolom Debet/Kredit gaya 1.234,56 dan satu baris dengan nominal rusak
+			file: "bri_estatement.csv", format: "bri", account: "0000111122223333",
+			ledgerBalance: 215000000, ledgerDate: "2024-10-02",
+			rows: []wantBankRow{
+				{line: 3, date: "2024-10-01", amount: 15000000, typ: "expense", description: "TRANSFER KE PENERIMA CONTOH", reference: "bri:20241001:e15000000:185000000"},
+				{line: 4, date: "2024-10-02", amount: 30000000, typ: "income", description: "SETORAN TUNAI", reference: "bri:20241002:i30000000:215000000"},
+				{line: 5, date: "2024-10-02", description: "BARIS RUSAK", err: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			imp, err := Detect(data)
+			if err != nil {
+				t.Fatalf("Detect: %v", err)
+			}
+			if imp.Name() != tt.format {
+				t.Fatalf("Detect = %s, want %s", imp.Name(), tt.format)
+			}
+			stmt, err := imp.Parse(data)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			if stmt.AccountNumber != tt.account {
+				t.Errorf("AccountNumber = %q, want %q", stmt.AccountNumber, tt.account)
+			}
+			if stmt.Currency != "IDR" {
+				t.Errorf("Currency = %q, want IDR", stmt.Currency)
+			}
+			switch {
+			case tt.ledgerBalance < 0 && stmt.LedgerBalance != nil:
+				t.Errorf("LedgerBalance = %d, want none", *stmt.LedgerBalance)
+			case tt.ledgerBalance >= 0 && (stmt.LedgerBalance == nil || *stmt.LedgerBalance != tt.ledgerBalance):
+				t.Errorf("LedgerBalance = %v, want %d", stmt.LedgerBalance, tt.ledgerBalance)
+			}
+			if got := dateString(stmt.LedgerDate); got != tt.ledgerDate {
+				t.Errorf("LedgerDate = %q, want %q", got, tt.ledgerDate)
+			}
+
+			if len(stmt.Rows) != len(tt.rows) {
+				t.Fatalf("got %d rows, want %d: %+v", len(stmt.Rows), len(tt.rows), stmt.Rows)
+			}
+			for i, want := range tt.rows {
+				got := stmt.Rows[i]
+				if got.Line != want.line || dateString(got.Date) != want.date || got.Description != want.description {
+					t.Errorf("row %d = line %d, date %q, %q; want line %d, date %q, %q",
+						i, got.Line, dateString(got.Date), got.Description, want.line, want.date, want.description)
+				}
+				if (got.Error != "") != want.err {
+					t.Errorf("row %d error = %q, want error %v", i, got.Error, want.err)
+				}
+				if (got.Skipped != "") != want.skipped {
+					t.Errorf("row %d skipped = %q, want skipped %v", i, got.Skipped, want.skipped)
+				}
+				if want.err {
+					continue
+				}
+				if got.Amount != want.amount || got.Type != want.typ || got.Reference != want.reference {
+					t.Errorf("row %d = %d %s ref %q; want %d %s ref %q",
+						i, got.Amount, got.Type, got.Reference, want.amount, want.typ, want.reference)
+				}
+				if got.Category != DefaultCategory {
+					t.Errorf("row %d category = %q, want %q", i, got.Category, DefaultCategory)
+				}
+			}
+		})
+	}
+}
+
+func TestParseIndonesianDate(t *testing.T) {
+	tests := []struct {
+		raw         string
+		defaultYear int
+		want        string // Kosong jika harus gagal
+	}{
+		{"01 Okt 2024", 0, "2024-10-01"},
+		{"1-Oktober-24", 0, "2024-10-01"},
+		{"01/Okt/2024 10:11:12", 0, "2024-10-01"},
+		{"17 Agustus 2024", 0, "2024-08-17"},
+		{"30 Nop 2024", 0, "2024-11-30"},
+		{"'05/03/2024", 0, "2024-03-05"},
+		{"05/03/24 08:15:00", 0, "2024-03-05"},
+		{"2024-03-05", 0, "2024-03-05"},
+		{"'29/02", 2024, "2024-02-29"},
+		{"29/02", 2023, ""},
+		{"01/10", 0, ""},
+		{"31 Feb 2024", 0, ""},
+		{"01 Okto 2024", 0, ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseIndonesianDate(tt.raw, tt.defaultYear)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ParseIndonesianDate(%q, %d) = %s, want error", tt.raw, tt.defaultYear, dateString(got))
+			}
+			continue
+		}
+		if err != nil || dateString(got) != tt.want {
+			t.Errorf("ParseIndonesianDate(%q, %d) = %s, %v; want %s", tt.raw, tt.defaultYear, dateString(got), err, tt.want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		raw   string
+		style string
+		want  int64
+		err   bool
+	}{
+		{"1,234,567.89", DecimalDot, 123456789, false},
+		{"1.234.567,89", DecimalComma, 123456789, false},
+		{"Rp 25.000", DecimalComma, 2500000, false},
+		{".00", DecimalDot, 0, false},
+		{"0,00", DecimalComma, 0, false},
+		{"(150,000.00)", DecimalDot, -15000000, false},
+		{"150.000,00-", DecimalComma, -15000000, false},
+		{"1.5", DecimalDot, 150, false},
+		{"1.234", DecimalDot, 0, true},
+		{"abc", DecimalDot, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.raw, tt.style)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseAmount(%q, %s) = %d, %v; want %d (error %v)", tt.raw, tt.style, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestDetectDecimalStyle(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   string
+	}{
+		{[]string{"150,000.00", "1,850,000.00"}, DecimalDot},
+		{[]string{"150.000,00", "1.850.000,00"}, DecimalComma},
+		{[]string{"25.000", "10.000"}, DecimalComma},
+		{[]string{"150000.00", "0.00"}, DecimalDot},
+	}
+	for _, tt := range tests {
+		if got := DetectDecimalStyle(tt.values); got != tt.want {
+			t.Errorf("DetectDecimalStyle(%q) = %s, want %s", tt.values, got, tt.want)
+		}
+	}
+}
+
+func dateString(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+)
+
+// StatementImporter adalah parser untuk satu format file mutasi rekening.
+// Setiap format baru cukup mengimplementasikan interface ini lalu didaftarkan di registry.
+type StatementImporter interface {
+	// Name adalah ID format yang dikirim client, misal "ofx" atau "bca"
+	Name() string
+	// Detect bernilai true jika isi file kemungkinan besar berformat ini
+	Detect(data []byte) bool
+	Parse(data []byte) (*Statement, error)
+}
+
+// registry berisi semua importer yang tersedia. Urutan penting untuk deteksi
+// otomatis: format yang lebih spesifik harus dicek lebih dulu.
+var registry = []StatementImporter{
+	ofxImporter{},
+	camtImporter{},
+	mt940Importer{},
+	qifImporter{},
+	bcaImporter{},
+	mandiriImporter{},
+	bniImporter{},
+	briImporter{},
+}
+
+var ErrUnknownFormat = errors.New("unknown statement format")
+
+// Lookup mencari importer berdasarkan nama format
+func Lookup(name string) (StatementImporter, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, imp := range registry {
+		if imp.Name() == name {
+			return imp, nil
+		}
+	}
+	return nil, ErrUnknownFormat
+}
+
+// Detect mencari importer pertama yang mengenali isi file
+func Detect(data []byte) (StatementImporter, error) {
+	for _, imp := range registry {
+		if imp.Detect(data) {
+			return imp, nil
+		}
+	}
+	return nil, ErrUnknownFormat
+}
+
+// Formats mengembalikan nama semua format yang didukung
+func Formats() []string {
+	names := make([]string, 0, len(registry))
+	for _, imp := range registry {
+		names = append(names, imp.Name())
+	}
+	return names
+}
+
+type ofxImporter struct{}
+
+func (ofxImporter) Name() string { return "ofx" }
+
+func (ofxImporter) Detect(data []byte) bool {
+	head := strings.ToUpper(string(data[:min(len(data), 4096)]))
+	return strings.Contains(head, "OFXHEADER") || strings.Contains(head, "<OFX>")
+}
+
+func (ofxImporter) Parse(data []byte) (*Statement, error) {
+	return ParseOFX(data)
+}
@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+)
+
+// mandiriImporter membaca ekspor mutasi Bank Mandiri. Dua varian didukung:
+//
+// Mandiri Online / MCM:
+//
+//	Account No,Date,Val. Date,Transaction Code,Description,Description,Reference No.,Debit,Credit
+//	1234567890,01/10/24,01/10/24,9999,TRANSFER KE,BUDI,000123,"150,000.00",.00
+//
+// Livin' by Mandiri (e-statement):
+//
+//	No,Tanggal,Keterangan,Dana Masuk (IDR),Dana Keluar (IDR),Saldo (IDR)
+//	1,01 Okt 2024,Transfer dari BUDI,"1.000.000,00",,"2.000.000,00"
+type mandiriImporter struct{}
+
+var mandiriColumns = bankColumns{
+	date:        []string{"date", "tanggal", "tanggal transaksi", "posting date"},
+	description: []string{"description", "keterangan", "remark", "remarks"},
+	debit:       []string{"debit", "dana keluar", "dana keluar idr", "debet"},
+	credit:      []string{"credit", "dana masuk", "dana masuk idr", "kredit"},
+	balance:     []string{"saldo", "saldo idr", "balance"},
+	reference:   []string{"reference no", "no referensi", "reference"},
+}
+
+func (mandiriImporter) Name() string { return "mandiri" }
+
+func (mandiriImporter) Detect(data []byte) bool {
+	head := strings.ToLower(string(data[:min(len(data), 4096)]))
+	online := strings.Contains(head, "account no") && strings.Contains(head, "val. date")
+	livin := strings.Contains(head, "dana masuk") && strings.Contains(head, "dana keluar")
+	return (online || livin) && hasHeader(data, mandiriColumns)
+}
+
+func (mandiriImporter) Parse(data []byte) (*Statement, error) {
+	records, lines, err := readCSVLines(data, DetectDelimiter(data))
+	if err != nil {
+		return nil, err
+	}
+
+	headerRow, idx, ok := locateBankHeader(records, mandiriColumns)
+	if !ok {
+		return nil, errors.New("Mandiri statement header not found")
+	}
+
+	stmt := &Statement{Currency: "IDR"}
+	header := records[headerRow]
+	for i, cell := range header {
+		if normalizeHeader(cell) == "account no" && len(records) > headerRow+1 {
+			stmt.AccountNumber = stripQuote(column(records[headerRow+1], i))
+		}
+	}
+
+	parseBankTable(records, lines, headerRow, idx, bankTableOptions{bank: "mandiri"}, stmt)
+	return stmt, nil
+}
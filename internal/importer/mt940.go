@@ -0,0 +1,188 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// mt940Importer membaca rekening koran SWIFT MT940:
+//
+//	:20:STATEMENT1
+//	:25:1234567890
+//	:28C:00001/001
+//	:60F:C241001IDR1000000,00
+//	:61:2410051005D150000,00NTRFNONREF//BANKREF123
+//	:86:PEMBAYARAN PLN
+//	:62F:C241031IDR850000,00
+//	-
+type mt940Importer struct{}
+
+// :61: tanggal valuta (YYMMDD), tanggal buku (MMDD, opsional), D/C/RD/RC, kode dana (opsional),
+// nominal, kode transaksi (N/F/S + 3 karakter), referensi nasabah, //referensi bank
+var mt940Line61 = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d{0,2})([NSF][A-Z0-9]{3})([^/]*)(?://(.*))?$`)
+
+// :62F: dan :60F: D/C, tanggal, mata uang, nominal
+var mt940Balance = regexp.MustCompile(`^(C|D)(\d{6})([A-Z]{3})(\d+,\d{0,2})`)
+
+// Kode terstruktur ?20..?29 dsb. di dalam :86: (format bank Jerman dan sebagian bank Asia)
+var mt940SubfieldCode = regexp.MustCompile(`\?\d{2}`)
+
+type mt940Field struct {
+	tag   string
+	value string
+	line  int
+}
+
+func (mt940Importer) Name() string { return "mt940" }
+
+func (mt940Importer) Detect(data []byte) bool {
+	head := data[:min(len(data), 4096)]
+	return bytes.Contains(head, []byte(":20:")) &&
+		(bytes.Contains(head, []byte(":60F:")) || bytes.Contains(head, []byte(":61:")))
+}
+
+// splitMT940Fields memecah file menjadi field ":TAG:nilai". Nilai bisa lebih dari satu baris.
+func splitMT940Fields(data []byte) ([]mt940Field, error) {
+	var fields []mt940Field
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		// Pembungkus blok SWIFT: {1:...}{2:...}{4: di awal, -} di akhir
+		if i := strings.Index(line, "{4:"); i >= 0 {
+			line = line[i+3:]
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "-" || trimmed == "-}" || trimmed == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			if end := strings.Index(line[1:], ":"); end > 0 && end <= 4 {
+				fields = append(fields, mt940Field{tag: line[1 : end+1], value: line[end+2:], line: lineNo})
+				continue
+			}
+		}
+		if len(fields) > 0 {
+			last := &fields[len(fields)-1]
+			last.value += "\n" + line
+		}
+	}
+	return fields, scanner.Err()
+}
+
+func parseMT940Date(yymmdd string) (time.Time, error) {
+	return time.Parse("060102", yymmdd)
+}
+
+func (mt940Importer) Parse(data []byte) (*Statement, error) {
+	fields, err := splitMT940Fields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &Statement{}
+	var current *models.ImportRow
+	for _, f := range fields {
+		switch f.tag {
+		case "25":
+			if stmt.AccountNumber == "" {
+				stmt.AccountNumber = strings.TrimSpace(f.value)
+			}
+		case "60F", "60M":
+			if m := mt940Balance.FindStringSubmatch(strings.TrimSpace(f.value)); m != nil && stmt.Currency == "" {
+				stmt.Currency = m[3]
+			}
+		case "61":
+			row := parseMT940Transaction(f)
+			stmt.Rows = append(stmt.Rows, row)
+			current = &stmt.Rows[len(stmt.Rows)-1]
+		case "86":
+			if current != nil {
+				text := mt940SubfieldCode.ReplaceAllString(f.value, " ")
+				text = strings.Join(strings.Fields(text), " ")
+				if current.Description == "" {
+					current.Description = text
+				} else {
+					current.Description = strings.TrimSpace(current.Description + " " + text)
+				}
+				current = nil // :86: hanya milik :61: tepat di atasnya
+			}
+		case "62F", "62M":
+			m := mt940Balance.FindStringSubmatch(strings.TrimSpace(f.value))
+			if m == nil {
+				continue
+			}
+			amount, err := ParseAmount(m[4], DecimalComma)
+			if err != nil {
+				continue
+			}
+			if m[1] == "D" {
+				amount = -amount
+			}
+			stmt.LedgerBalance = &amount
+			stmt.LedgerDate, _ = parseMT940Date(m[2])
+			stmt.Currency = m[3]
+		}
+	}
+
+	if len(stmt.Rows) == 0 && stmt.LedgerBalance == nil {
+		return nil, errors.New("no :61: statement lines found in MT940 file")
+	}
+	return stmt, nil
+}
+
+func parseMT940Transaction(f mt940Field) models.ImportRow {
+	row := models.ImportRow{Line: f.line, Category: DefaultCategory}
+
+	// Baris pertama berisi data terstruktur, baris berikutnya informasi tambahan
+	first, extra, _ := strings.Cut(f.value, "\n")
+	m := mt940Line61.FindStringSubmatch(strings.TrimSpace(first))
+	if m == nil {
+		row.Error = fmt.Sprintf("invalid :61: line %q", first)
+		return row
+	}
+
+	date, err := parseMT940Date(m[1])
+	if err != nil {
+		row.Error = fmt.Sprintf("invalid value date %q", m[1])
+		return row
+	}
+	row.Date = date
+
+	amount, err := ParseAmount(m[5], DecimalComma)
+	if err != nil || amount == 0 {
+		row.Error = fmt.Sprintf("invalid amount %q", m[5])
+		return row
+	}
+	row.Amount = amount
+
+	// RD = pembatalan debit (uang kembali), RC = pembatalan kredit
+	switch m[3] {
+	case "D", "RC":
+		row.Type = "expense"
+	default:
+		row.Type = "income"
+	}
+
+	customerRef := strings.TrimSpace(m[7])
+	bankRef := strings.TrimSpace(m[8])
+	switch {
+	case bankRef != "":
+		row.Reference = bankRef
+	case customerRef != "" && customerRef != "NONREF":
+		row.Reference = customerRef
+	}
+
+	row.Description = strings.Join(strings.Fields(extra), " ")
+	return row
+}
@@ -0,0 +1,205 @@
+package importer
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// Statement adalah hasil parsing satu file mutasi rekening dari bank
+type Statement struct {
+	AccountNumber string
+	Currency      string
+	LedgerBalance *int64 // nil jika file tidak menyertakan saldo akhir
+	LedgerDate    time.Time
+	Rows          []models.ImportRow
+}
+
+type ofxToken struct {
+	name    string
+	value   string // Kosong untuk tag pembuka aggregate
+	closing bool
+	line    int
+}
+
+// tokenizeOFX memecah isi OFX menjadi token tag. Cara ini bekerja untuk OFX 1.x (SGML,
+// tag daun tanpa penutup) maupun 2.x (XML), karena nilai tag daun selalu berakhir di '<' berikutnya.
+func tokenizeOFX(body string, startLine int) []ofxToken {
+	var tokens []ofxToken
+	line := startLine
+	pos := 0
+	for {
+		open := strings.IndexByte(body[pos:], '<')
+		if open < 0 {
+			break
+		}
+		line += strings.Count(body[pos:pos+open], "\n")
+		pos += open
+
+		end := strings.IndexByte(body[pos:], '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.TrimSpace(body[pos+1 : pos+end])
+		pos += end + 1
+
+		if tag == "" || tag[0] == '?' || tag[0] == '!' {
+			continue // Deklarasi XML atau komentar
+		}
+
+		tok := ofxToken{line: line}
+		if tag[0] == '/' {
+			tok.closing = true
+			tag = tag[1:]
+		}
+		if i := strings.IndexAny(tag, " \t\r\n"); i >= 0 {
+			tag = tag[:i] // Buang atribut XML
+		}
+		tok.name = strings.ToUpper(strings.TrimSuffix(tag, "/"))
+
+		if !tok.closing {
+			next := strings.IndexByte(body[pos:], '<')
+			if next < 0 {
+				next = len(body) - pos
+			}
+			tok.value = html.UnescapeString(strings.TrimSpace(body[pos : pos+next]))
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens
+}
+
+// parseOFXDate membaca format YYYYMMDD[HHMMSS[.XXX][TZ]]. Hanya tanggal yang dipakai
+// agar transaksi tidak bergeser hari karena zona waktu.
+func parseOFXDate(s string) (time.Time, error) {
+	if len(s) < 8 {
+		return time.Time{}, errors.New("invalid OFX date")
+	}
+	return time.Parse("20060102", s[:8])
+}
+
+func parseOFXAmount(s string) (int64, error) {
+	style := DecimalDot
+	if strings.Contains(s, ",") && !strings.Contains(s, ".") {
+		style = DecimalComma // Beberapa bank Eropa memakai koma
+	}
+	return ParseAmount(s, style)
+}
+
+// toUTF8 mengubah isi file berkodean Windows-1252/Latin-1 (umum di OFX 1.x) menjadi UTF-8
+func toUTF8(data []byte) string {
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// ParseOFX mem-parsing file OFX/QFX versi 1.x (SGML) maupun 2.x (XML),
+// baik dari rekening bank (STMTRS) maupun kartu kredit (CCSTMTRS).
+func ParseOFX(data []byte) (*Statement, error) {
+	text := toUTF8(data)
+	start := strings.Index(strings.ToUpper(text), "<OFX>")
+	if start < 0 {
+		return nil, errors.New("not an OFX file: missing <OFX> element")
+	}
+	tokens := tokenizeOFX(text[start:], strings.Count(text[:start], "\n")+1)
+
+	stmt := &Statement{}
+	var current map[string]string
+	var currentLine int
+	inLedger := false
+
+	finish := func() {
+		if current != nil {
+			stmt.Rows = append(stmt.Rows, ofxRow(current, currentLine))
+			current = nil
+		}
+	}
+
+	for _, tok := range tokens {
+		switch {
+		case tok.name == "STMTTRN" && !tok.closing:
+			finish() // SGML yang tidak menutup STMTTRN sebelumnya
+			current = make(map[string]string)
+			currentLine = tok.line
+		case tok.name == "STMTTRN" && tok.closing, tok.name == "BANKTRANLIST" && tok.closing:
+			finish()
+		case tok.name == "LEDGERBAL":
+			inLedger = !tok.closing
+		case tok.closing || tok.value == "":
+			// Tag penutup atau pembuka aggregate lain, tidak ada nilai
+		case current != nil:
+			current[tok.name] = tok.value
+		case inLedger && tok.name == "BALAMT":
+			if amount, err := parseOFXAmount(tok.value); err == nil {
+				stmt.LedgerBalance = &amount
+			}
+		case inLedger && tok.name == "DTASOF":
+			stmt.LedgerDate, _ = parseOFXDate(tok.value)
+		case tok.name == "CURDEF" && stmt.Currency == "":
+			stmt.Currency = tok.value
+		case tok.name == "ACCTID" && stmt.AccountNumber == "":
+			stmt.AccountNumber = tok.value
+		}
+	}
+	finish()
+
+	if len(stmt.Rows) == 0 && stmt.LedgerBalance == nil {
+		return nil, errors.New("no transactions found in OFX file")
+	}
+	return stmt, nil
+}
+
+// ofxRow mengubah satu <STMTTRN> menjadi ImportRow
+func ofxRow(fields map[string]string, line int) models.ImportRow {
+	row := models.ImportRow{
+		Line:      line,
+		Category:  DefaultCategory,
+		Reference: fields["FITID"],
+	}
+
+	name, memo := fields["NAME"], fields["MEMO"]
+	switch {
+	case name == "":
+		row.Description = memo
+	case memo == "" || memo == name:
+		row.Description = name
+	default:
+		row.Description = name + " - " + memo
+	}
+
+	date, err := parseOFXDate(fields["DTPOSTED"])
+	if err != nil {
+		row.Error = "invalid or missing DTPOSTED"
+		return row
+	}
+	row.Date = date
+
+	amount, err := parseOFXAmount(fields["TRNAMT"])
+	if err != nil {
+		row.Error = "invalid or missing TRNAMT"
+		return row
+	}
+	if amount == 0 {
+		row.Error = "amount cannot be zero"
+		return row
+	}
+
+	// Tanda TRNAMT yang menentukan arah uang, bukan TRNTYPE: refund POS ditulis positif
+	if amount < 0 {
+		row.Type = "expense"
+		amount = -amount
+	} else {
+		row.Type = "income"
+	}
+	row.Amount = amount
+	return row
+}
@@ -0,0 +1,41 @@
+package importer
+
+import "testing"
+
+func TestOFXAmountSign(t *testing.T) {
+	data := []byte(`OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>IDR
+<BANKACCTFROM><BANKID>014<ACCTID>0001112223<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>POS<DTPOSTED>20241001<TRNAMT>-150000.00<FITID>TRX-1<NAME>TOKO CONTOH</STMTTRN>
+<STMTTRN><TRNTYPE>POS<DTPOSTED>20241002<TRNAMT>150000.00<FITID>TRX-2<NAME>TOKO CONTOH</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`)
+
+	stmt, err := ofxImporter{}.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"expense",
+		"income", // Refund POS: TRNAMT positif berarti uang kembali masuk
+	}
+	if len(stmt.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(stmt.Rows), len(want))
+	}
+	for i, typ := range want {
+		got := stmt.Rows[i]
+		if got.Error != "" {
+			t.Fatalf("row %d: %s", i, got.Error)
+		}
+		if got.Type != typ || got.Amount != 15000000 {
+			t.Errorf("row %d = %s %d, want %s 15000000", i, got.Type, got.Amount, typ)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// qifImporter membaca Quicken Interchange Format yang diekspor aplikasi desktop lama
+// (Quicken, MS Money, GnuCash, HomeBank):
+//
+//	!Type:Bank
+//	D10/01'24
+//	T-150,000.00
+//	PIndomaret
+//	LMakanan
+//	^
+//
+// Kategori berbentuk [Nama Akun] berarti transfer ke/dari akun tersebut.
+type qifImporter struct{}
+
+// Bagian !Type yang berisi transaksi. Bagian lain (Invst, Cat, Class, Memorized) dilewati.
+var qifTransactionTypes = map[string]bool{
+	"bank": true, "cash": true, "ccard": true, "oth a": true, "oth l": true,
+}
+
+func (qifImporter) Name() string { return "qif" }
+
+func (qifImporter) Detect(data []byte) bool {
+	head := strings.ToLower(string(bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \r\n\t")))
+	return strings.HasPrefix(head, "!type:") || strings.HasPrefix(head, "!account") || strings.HasPrefix(head, "!option")
+}
+
+type qifRecord struct {
+	line   int
+	fields map[byte]string
+}
+
+func (qifImporter) Parse(data []byte) (*Statement, error) {
+	var records []qifRecord
+	inTransactions := false
+	current := qifRecord{fields: make(map[byte]string)}
+
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if line[0] == '!' {
+			header := strings.ToLower(strings.TrimSpace(line[1:]))
+			if typ, ok := strings.CutPrefix(header, "type:"); ok {
+				inTransactions = qifTransactionTypes[strings.TrimSpace(typ)]
+			} else if header == "account" {
+				inTransactions = false // Blok info akun, diakhiri '^'
+			}
+			continue
+		}
+		if !inTransactions {
+			continue
+		}
+
+		if line[0] == '^' {
+			if len(current.fields) > 0 {
+				records = append(records, current)
+			}
+			current = qifRecord{fields: make(map[byte]string)}
+			continue
+		}
+		if len(current.fields) == 0 {
+			current.line = lineNo
+		}
+		code := line[0]
+		if code == 'S' || code == 'E' || code == '$' || code == '%' {
+			continue // Split: cukup total di field T
+		}
+		if _, exists := current.fields[code]; !exists {
+			current.fields[code] = strings.TrimSpace(line[1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(current.fields) > 0 {
+		records = append(records, current) // Record terakhir tanpa '^'
+	}
+	if len(records) == 0 {
+		return nil, errors.New("no bank transactions found in QIF file")
+	}
+
+	// Format tanggal dan desimal ditentukan dari seluruh file
+	var dates, amounts []string
+	for _, rec := range records {
+		dates = append(dates, rec.fields['D'])
+		amount := rec.fields['T']
+		if amount == "" {
+			amount = rec.fields['U']
+		}
+		amounts = append(amounts, amount)
+	}
+	dayFirst := qifDayFirst(dates)
+	style := DetectDecimalStyle(amounts)
+
+	stmt := &Statement{}
+	for i, rec := range records {
+		stmt.Rows = append(stmt.Rows, qifRow(rec, amounts[i], dayFirst, style))
+	}
+	return stmt, nil
+}
+
+// splitQIFDate memecah "10/01'24", "10/ 1/2024", "2024-10-01" menjadi tiga angka
+func splitQIFDate(s string) ([]int, error) {
+	s = strings.NewReplacer("'", "/", "-", "/", ".", "/", " ", "").Replace(strings.TrimSpace(s))
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid date %q", s)
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", s)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+// qifDayFirst menebak apakah tanggal berformat hari/bulan. QIF aslinya memakai
+// bulan/hari (Quicken versi Amerika), jadi itu dipakai jika tidak ada petunjuk.
+func qifDayFirst(dates []string) bool {
+	for _, d := range dates {
+		nums, err := splitQIFDate(d)
+		if err != nil || nums[0] > 31 {
+			continue // Tahun di depan, tidak ambigu
+		}
+		if nums[0] > 12 {
+			return true
+		}
+		if nums[1] > 12 {
+			return false
+		}
+	}
+	return false
+}
+
+func parseQIFDate(s string, dayFirst bool) (time.Time, error) {
+	nums, err := splitQIFDate(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var year, month, day int
+	switch {
+	case nums[0] > 31:
+		year, month, day = nums[0], nums[1], nums[2]
+	case dayFirst:
+		day, month, year = nums[0], nums[1], nums[2]
+	default:
+		month, day, year = nums[0], nums[1], nums[2]
+	}
+	if year < 100 {
+		year += 2000
+	}
+	return validDate(year, time.Month(month), day)
+}
+
+func qifRow(rec qifRecord, amountStr string, dayFirst bool, style string) models.ImportRow {
+	f := rec.fields
+	row := models.ImportRow{Line: rec.line}
+
+	payee, memo := f['P'], f['M']
+	switch {
+	case payee == "":
+		row.Description = memo
+	case memo == "" || memo == payee:
+		row.Description = payee
+	default:
+		row.Description = payee + " - " + memo
+	}
+
+	date, err := parseQIFDate(f['D'], dayFirst)
+	if err != nil {
+		row.Error = "invalid or missing date (D)"
+		return row
+	}
+	row.Date = date
+
+	amount, err := ParseAmount(amountStr, style)
+	if err != nil {
+		row.Error = "invalid or missing amount (T)"
+		return row
+	}
+	if amount == 0 {
+		row.Error = "amount cannot be zero"
+		return row
+	}
+
+	category := f['L']
+	if strings.HasPrefix(category, "[") && strings.HasSuffix(category, "]") {
+		// Transfer: uang keluar ke akun lain, atau masuk dari akun lain
+		other := strings.TrimSpace(category[1 : len(category)-1])
+		row.Type = "transfer"
+		row.Category = TransferCategory
+		if amount < 0 {
+			row.DestinationAccountName = other
+			row.Amount = -amount
+		} else {
+			row.AccountName = other
+			row.Amount = amount
+		}
+		return row
+	}
+
+	if amount < 0 {
+		row.Type = "expense"
+		amount = -amount
+	} else {
+		row.Type = "income"
+	}
+	row.Amount = amount
+
+	// Kategori "Makanan:Restoran" disimpan utuh, kecuali terlalu panjang untuk kolom kategori
+	if category, _, _ = strings.Cut(category, "/"); len(category) > 50 {
+		category, _, _ = strings.Cut(category, ":")
+	}
+	row.Category = category
+	if row.Category == "" {
+		row.Category = DefaultCategory
+	}
+	return row
+}
@@ -9,3 +9,16 @@ type Account struct {
 	CurrentBalance int64     `json:"current_balance"` // Saldo dalam 'sen'
 	CreatedAt      time.Time `json:"created_at"`
 }
+
+// AccountPeriodBalance adalah saldo dan arus kas satu akun dalam suatu periode
+type AccountPeriodBalance struct {
+	AccountID      int64  `json:"account_id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	OpeningBalance int64  `json:"opening_balance"`
+	Income         int64  `json:"income"`
+	Expense        int64  `json:"expense"`
+	TransferIn     int64  `json:"transfer_in"`
+	TransferOut    int64  `json:"transfer_out"`
+	ClosingBalance int64  `json:"closing_balance"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+const (
+	BackupFormat = "finance-tracker-backup"
+	// BackupVersion dinaikkan setiap kali isi arsip berubah secara tidak kompatibel.
+	// Versi 2 menambahkan template budget, hari libur dan pengaturan aplikasi.
+	BackupVersion = 2
+)
+
+// Strategi restore jika data di arsip sudah ada di database
+const (
+	RestoreSkip      = "skip"      // Data yang sudah ada dipertahankan
+	RestoreOverwrite = "overwrite" // Data yang sudah ada ditimpa isi arsip
+	RestoreReplace   = "replace"   // Semua data dihapus dulu, lalu diisi ulang dari arsip
+)
+
+// Backup adalah arsip JSON berisi seluruh data aplikasi. ID di dalam arsip hanya
+// dipakai untuk relasi antar data dan akan dipetakan ulang saat restore.
+type Backup struct {
+	Format       string                 `json:"format"`
+	Version      int                    `json:"version"`
+	CreatedAt    time.Time              `json:"created_at"`
+	Accounts     []Account              `json:"accounts"`
+	Categories   []Category             `json:"categories"`
+	Transactions []Transaction          `json:"transactions"`
+	Budgets      []Budget               `json:"budgets"`
+	Recurring    []RecurringTransaction `json:"recurring"`
+	// Sejak versi 2
+	BudgetTemplates []BudgetTemplate `json:"budget_templates"`
+	Holidays        []Holiday        `json:"holidays"`
+	Settings        *AppSettings     `json:"settings,omitempty"`
+}
+
+// RestoreCounts adalah jumlah baris per jenis data yang diproses saat restore
+type RestoreCounts struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+type RestoreResult struct {
+	Strategy     string        `json:"strategy"`
+	Accounts     RestoreCounts `json:"accounts"`
+	Categories   RestoreCounts `json:"categories"`
+	Transactions RestoreCounts `json:"transactions"`
+	Budgets      RestoreCounts `json:"budgets"`
+	Recurring    RestoreCounts `json:"recurring"`
+	// Sejak versi 2
+	BudgetTemplates RestoreCounts `json:"budget_templates"`
+	Holidays        RestoreCounts `json:"holidays"`
+	Settings        RestoreCounts `json:"settings"`
+}
@@ -2,11 +2,142 @@ package models
 
 import "time"
 
+// Aturan rollover: apa yang terjadi dengan sisa budget bulan ini di bulan berikutnya
+const (
+	RolloverNone              = "none"
+	RolloverSurplus           = "surplus"             // Sisa positif dibawa ke bulan berikutnya
+	RolloverSurplusAndDeficit = "surplus_and_deficit" // Kekurangan juga dibawa (mengurangi budget berikutnya)
+	RolloverCapped            = "capped"              // Sisa positif dibawa, maksimal RolloverCap
+)
+
+// Jenis periode budget. Budget tetap disimpan per bulan & tahun awal periodenya.
+const (
+	PeriodWeekly  = "weekly"  // Amount per minggu, minggu mulai hari PeriodStartDay (1 = Senin ... 7 = Minggu)
+	PeriodMonthly = "monthly" // Bulan kalender
+	PeriodCustom  = "custom"  // Mulai tanggal PeriodStartDay (misal tanggal gajian) sampai sehari sebelum tanggal itu bulan depan
+	PeriodYearly  = "yearly"  // 12 bulan mulai tanggal 1 bulan tersebut
+)
+
 type Budget struct {
-	ID           int64     `json:"id"`
-	CategoryName string    `json:"category_name"`
-	Amount       int64     `json:"amount"` // dalam 'sen'
-	Month        int       `json:"month"`
-	Year         int       `json:"year"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID             int64     `json:"id"`
+	CategoryName   string    `json:"category_name"`
+	Amount         int64     `json:"amount"` // dalam 'sen'
+	Month          int       `json:"month"`
+	Year           int       `json:"year"`
+	Rollover       string    `json:"rollover"`
+	RolloverCap    *int64    `json:"rollover_cap,omitempty"`
+	PeriodType     string    `json:"period_type"`
+	PeriodStartDay *int      `json:"period_start_day,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	// Dihitung dari bulan-bulan sebelumnya, tidak disimpan
+	CarriedOver int64 `json:"carried_over"` // Sisa (atau kekurangan) yang dibawa dari bulan lalu
+	Available   int64 `json:"available"`    // Amount + CarriedOver
+
+	// Rentang tanggal periode (untuk budget mingguan: minggu yang sedang berjalan), tidak disimpan
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+}
+
+// BudgetActual membandingkan budget satu kategori dengan pengeluaran sebenarnya
+type BudgetActual struct {
+	CategoryName string `json:"category_name"`
+	Month        int    `json:"month"`
+	Year         int    `json:"year"`
+	Budget       int64  `json:"budget"`
+	Actual       int64  `json:"actual"`
+}
+
+// BudgetProgress adalah realisasi satu kategori yang punya budget di bulan tertentu
+type BudgetProgress struct {
+	CategoryName   string    `json:"category_name"`
+	PeriodType     string    `json:"period_type"`
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"`
+	DaysInPeriod   int       `json:"days_in_period"`
+	DaysElapsed    int       `json:"days_elapsed"`
+	Limit          int64     `json:"limit"`        // Budget yang tersedia, termasuk rollover
+	CarriedOver    int64     `json:"carried_over"` // Bagian dari Limit yang berasal dari bulan lalu
+	Spent          int64     `json:"spent"`
+	Remaining      int64     `json:"remaining"`       // Negatif jika melebihi budget
+	PercentUsed    float64   `json:"percent_used"`    // 0-100, bisa lebih dari 100
+	DailyBurnRate  int64     `json:"daily_burn_rate"` // Rata-rata pengeluaran per hari yang sudah lewat
+	ProjectedSpend int64     `json:"projected_spend"` // Perkiraan total pengeluaran di akhir periode
+}
+
+// UnbudgetedSpending adalah pengeluaran di kategori yang tidak punya budget
+type UnbudgetedSpending struct {
+	Spent      int64             `json:"spent"`
+	Categories []CategorySummary `json:"categories"`
+}
+
+type BudgetProgressResponse struct {
+	Month       int                `json:"month"`
+	Year        int                `json:"year"`
+	DaysInMonth int                `json:"days_in_month"`
+	DaysElapsed int                `json:"days_elapsed"`
+	Categories  []BudgetProgress   `json:"categories"`
+	Unbudgeted  UnbudgetedSpending `json:"unbudgeted"`
+}
+
+// BudgetItem adalah budget satu kategori tanpa bulan, dipakai untuk set massal dan template
+type BudgetItem struct {
+	CategoryName   string `json:"category_name"`
+	Amount         int64  `json:"amount"`
+	Rollover       string `json:"rollover"`
+	RolloverCap    *int64 `json:"rollover_cap,omitempty"`
+	PeriodType     string `json:"period_type"`
+	PeriodStartDay *int   `json:"period_start_day,omitempty"`
+}
+
+// BulkBudgetRequest adalah payload untuk PUT /api/budgets/{year}/{month}.
+// Jika Replace bernilai true, budget kategori lain di bulan itu dihapus.
+type BulkBudgetRequest struct {
+	Budgets []BudgetItem `json:"budgets"`
+	Replace bool         `json:"replace"`
+}
+
+// BudgetCopyRequest menyalin budget satu bulan ke bulan lain, atau ke rentang
+// bulan sampai ToEndMonth/ToEndYear (inklusif)
+type BudgetCopyRequest struct {
+	FromMonth  int  `json:"from_month"`
+	FromYear   int  `json:"from_year"`
+	ToMonth    int  `json:"to_month"`
+	ToYear     int  `json:"to_year"`
+	ToEndMonth int  `json:"to_end_month,omitempty"`
+	ToEndYear  int  `json:"to_end_year,omitempty"`
+	Overwrite  bool `json:"overwrite"` // Timpa budget yang sudah ada di bulan tujuan
+}
+
+type BudgetCopyResult struct {
+	Months  int `json:"months"`
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+// BudgetTemplate adalah kumpulan budget bernama yang bisa dipakai ulang
+type BudgetTemplate struct {
+	ID        int64        `json:"id"`
+	Name      string       `json:"name"`
+	Items     []BudgetItem `json:"items"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// BudgetTemplateRequest membuat template dari Items, atau dari budget bulan
+// FromMonth/FromYear jika Items kosong
+type BudgetTemplateRequest struct {
+	Name      string       `json:"name"`
+	Items     []BudgetItem `json:"items"`
+	FromMonth int          `json:"from_month,omitempty"`
+	FromYear  int          `json:"from_year,omitempty"`
+}
+
+// ApplyTemplateRequest menerapkan template ke satu bulan atau rentang bulan
+type ApplyTemplateRequest struct {
+	Month     int  `json:"month"`
+	Year      int  `json:"year"`
+	EndMonth  int  `json:"end_month,omitempty"`
+	EndYear   int  `json:"end_year,omitempty"`
+	Overwrite bool `json:"overwrite"`
 }
@@ -0,0 +1,44 @@
+package models
+
+// Envelope adalah saldo satu kategori di mode envelope (zero-based) untuk satu bulan.
+// Assigned disimpan di tabel budgets (kolom amount).
+type Envelope struct {
+	CategoryName string `json:"category_name"`
+	CarriedOver  int64  `json:"carried_over"` // Saldo envelope dari bulan lalu
+	Assigned     int64  `json:"assigned"`     // Uang yang dialokasikan bulan ini
+	Spent        int64  `json:"spent"`
+	Balance      int64  `json:"balance"`   // CarriedOver + Assigned - Spent
+	Overspent    int64  `json:"overspent"` // Kekurangan yang belum ditutup dari envelope lain
+}
+
+// EnvelopeMonth adalah ringkasan mode envelope untuk satu bulan
+type EnvelopeMonth struct {
+	Month int `json:"month"`
+	Year  int `json:"year"`
+	// StartMonth & StartYear adalah bulan pertama yang punya alokasi (awal mode envelope)
+	StartMonth int `json:"start_month"`
+	StartYear  int `json:"start_year"`
+
+	Income        int64      `json:"income"`   // Pemasukan bulan ini
+	Assigned      int64      `json:"assigned"` // Total alokasi bulan ini
+	ReadyToAssign int64      `json:"ready_to_assign"`
+	Envelopes     []Envelope `json:"envelopes"`
+}
+
+// EnvelopeAssignRequest menambah (atau mengurangi, jika negatif) alokasi sebuah envelope
+type EnvelopeAssignRequest struct {
+	CategoryName string `json:"category_name"`
+	Month        int    `json:"month"`
+	Year         int    `json:"year"`
+	Amount       int64  `json:"amount"`
+}
+
+// EnvelopeMoveRequest memindahkan uang antar envelope di bulan yang sama.
+// Untuk menutup overspending, Amount boleh 0: yang dipindahkan adalah kekurangan ToCategory.
+type EnvelopeMoveRequest struct {
+	FromCategory string `json:"from_category"`
+	ToCategory   string `json:"to_category"`
+	Month        int    `json:"month"`
+	Year         int    `json:"year"`
+	Amount       int64  `json:"amount"`
+}
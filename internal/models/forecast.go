@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// UpcomingOccurrence adalah satu kejadian jadwal berulang yang akan datang
+type UpcomingOccurrence struct {
+	RecurringID          int64     `json:"recurring_id"`
+	Date                 time.Time `json:"date"`
+	Amount               int64     `json:"amount"`
+	Type                 string    `json:"type"`
+	Category             string    `json:"category"`
+	Description          string    `json:"description"`
+	AccountID            int64     `json:"account_id"`
+	DestinationAccountID *int64    `json:"destination_account_id,omitempty"`
+	Overdue              bool      `json:"overdue"` // Sudah jatuh tempo tapi belum diposting worker
+}
+
+type UpcomingResponse struct {
+	StartDate   time.Time            `json:"start_date"`
+	EndDate     time.Time            `json:"end_date"`
+	Income      int64                `json:"income"`  // Total pemasukan yang akan datang
+	Expense     int64                `json:"expense"` // Total pengeluaran yang akan datang
+	Occurrences []UpcomingOccurrence `json:"occurrences"`
+}
+
+// ForecastDay adalah proyeksi saldo satu akun di akhir satu hari
+type ForecastDay struct {
+	Date     time.Time `json:"date"`
+	Inflow   int64     `json:"inflow"`  // Pemasukan + transfer masuk
+	Outflow  int64     `json:"outflow"` // Pengeluaran + transfer keluar
+	Balance  int64     `json:"balance"`
+	Negative bool      `json:"negative"`
+}
+
+// AccountForecast adalah proyeksi saldo harian satu akun
+type AccountForecast struct {
+	AccountID       int64         `json:"account_id"`
+	Name            string        `json:"name"`
+	StartingBalance int64         `json:"starting_balance"` // current_balance saat ini
+	EndingBalance   int64         `json:"ending_balance"`
+	LowestBalance   int64         `json:"lowest_balance"`
+	LowestDate      time.Time     `json:"lowest_date"`
+	NegativeDays    int           `json:"negative_days"`
+	FirstNegative   *time.Time    `json:"first_negative,omitempty"` // Hari pertama saldo di bawah nol
+	Days            []ForecastDay `json:"days"`
+}
+
+type ForecastResponse struct {
+	StartDate time.Time         `json:"start_date"`
+	EndDate   time.Time         `json:"end_date"`
+	Accounts  []AccountForecast `json:"accounts"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// ImportRow adalah satu baris hasil parsing file impor (CSV, OFX, dll)
+// sebelum disimpan sebagai transaksi.
+type ImportRow struct {
+	Line                   int       `json:"line"` // Nomor baris di file asli
+	Date                   time.Time `json:"date"`
+	Amount                 int64     `json:"amount"` // dalam 'sen', selalu positif
+	Type                   string    `json:"type"`   // 'income', 'expense', 'transfer'
+	Category               string    `json:"category"`
+	Description            string    `json:"description"`
+	AccountName            string    `json:"account_name,omitempty"`
+	DestinationAccountName string    `json:"destination_account_name,omitempty"`
+	Reference              string    `json:"reference,omitempty"` // ID unik dari bank (FITID, no. referensi)
+
+	Duplicate bool   `json:"duplicate"`         // Sudah ada transaksi yang sama di database
+	Skipped   string `json:"skipped,omitempty"` // Alasan baris sengaja dilewati (misal transaksi PEND)
+	Error     string `json:"error,omitempty"`   // Alasan baris ini tidak bisa diimpor
+}
+
+// CSVMapping menjelaskan cara membaca sebuah file CSV.
+// Columns memetakan nama field (date, amount, type, ...) ke indeks kolom (mulai dari 0).
+type CSVMapping struct {
+	Delimiter    string         `json:"delimiter"`
+	HasHeader    bool           `json:"has_header"`
+	DateFormat   string         `json:"date_format"`   // Layout Go, misal "02/01/2006"
+	DecimalStyle string         `json:"decimal_style"` // 'dot' (1,234.56) atau 'comma' (1.234,56)
+	Columns      map[string]int `json:"columns"`
+}
+
+// StatementBalance membandingkan saldo akhir menurut bank dengan saldo di aplikasi
+type StatementBalance struct {
+	Currency       string    `json:"currency,omitempty"`
+	LedgerBalance  int64     `json:"ledger_balance"` // Saldo menurut bank, dalam 'sen'
+	LedgerDate     time.Time `json:"ledger_date"`
+	CurrentBalance int64     `json:"current_balance"` // Saldo akun kita, dalam 'sen'
+	Difference     int64     `json:"difference"`      // ledger_balance - current_balance
+}
+
+type ImportPreview struct {
+	Mapping        *CSVMapping       `json:"mapping,omitempty"`
+	Headers        []string          `json:"headers,omitempty"`
+	Rows           []ImportRow       `json:"rows"`
+	TotalRows      int               `json:"total_rows"`
+	DuplicateCount int               `json:"duplicate_count"`
+	SkippedCount   int               `json:"skipped_count"`
+	ErrorCount     int               `json:"error_count"`
+	Balance        *StatementBalance `json:"balance,omitempty"`
+}
+
+type ImportResult struct {
+	Imported          int               `json:"imported"`
+	SkippedDuplicates int               `json:"skipped_duplicates"`
+	SkippedRows       int               `json:"skipped_rows"`
+	Balance           *StatementBalance `json:"balance,omitempty"`
+}
@@ -27,11 +27,21 @@ type TransactionExport struct {
 	DestinationAccountName sql.NullString `json:"destination_account_name"`
 }
 
+// TransactionFilter membatasi daftar dan ekspor transaksi. Nilai kosong berarti tanpa batas.
+type TransactionFilter struct {
+	StartDate time.Time
+	EndDate   time.Time
+	AccountID int64  // 0 berarti "Semua Akun"
+	Type      string // income, expense atau transfer
+	Category  string
+}
+
 type User struct {
 	ID           int64     `json:"id"`
 	Name         string    `json:"name,omitempty"`
 	Email        string    `json:"email"`
 	Password     string    `json:"password,omitempty"` // Hanya untuk input, jangan disimpan
 	PasswordHash string    `json:"-"`                  // Untuk database, jangan dikirim ke client
+	IsAdmin      bool      `json:"is_admin"`           // Boleh memakai endpoint /api/admin
 	CreatedAt    time.Time `json:"created_at"`
 }
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const NotificationBudgetAlert = "budget_alert"
+
+// Notification adalah pesan in-app; salinannya juga dikirim ke channel lain (email, webhook)
+type Notification struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Message   string          `json:"message"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type NotificationList struct {
+	Notifications []Notification `json:"notifications"`
+	UnreadCount   int            `json:"unread_count"`
+}
+
+// BudgetAlert dicatat saat pengeluaran sebuah kategori melewati ambang budget bulanannya
+type BudgetAlert struct {
+	ID           int64     `json:"id"`
+	CategoryName string    `json:"category_name"`
+	Month        int       `json:"month"` // Bulan & tahun budget
+	Year         int       `json:"year"`
+	PeriodType   string    `json:"period_type"`
+	PeriodStart  time.Time `json:"period_start"`
+	Threshold    int       `json:"threshold"` // dalam persen
+	Spent        int64     `json:"spent"`
+	Limit        int64     `json:"limit"` // Budget termasuk rollover
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -2,15 +2,78 @@ package models
 
 import "time"
 
+// Status jadwal berulang, dihitung dari paused_at, end_date dan max_occurrences
+const (
+	RecurringActive   = "active"
+	RecurringPaused   = "paused"
+	RecurringFinished = "finished" // Sudah lewat end_date atau mencapai max_occurrences
+)
+
+// Pergeseran kejadian yang jatuh di akhir pekan atau hari libur
+const (
+	ShiftNone     = "none"
+	ShiftPrevious = "previous" // Ke hari kerja sebelumnya
+	ShiftNext     = "next"     // Ke hari kerja berikutnya
+)
+
 type RecurringTransaction struct {
-	ID          int64     `json:"id"`
-	Amount      int64     `json:"amount"`
-	Type        string    `json:"type"`
-	Category    string    `json:"category"`
-	Description string    `json:"description"`
-	Frequency   string    `json:"frequency"` // 'daily', 'weekly', 'monthly', 'yearly'
-	Interval    int       `json:"interval"`
-	StartDate   time.Time `json:"start_date"`
-	NextDueDate time.Time `json:"next_due_date"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          int64  `json:"id"`
+	Amount      int64  `json:"amount"`
+	Type        string `json:"type"` // 'income', 'expense' atau 'transfer'
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	AccountID   int64  `json:"account_id"`
+	// Hanya untuk type 'transfer': akun yang menerima uang
+	DestinationAccountID *int64     `json:"destination_account_id,omitempty"`
+	Frequency            string     `json:"frequency"` // 'daily', 'weekly', 'monthly', 'yearly'
+	Interval             int        `json:"interval"`
+	RRule                string     `json:"rrule,omitempty"` // Aturan RFC 5545, menggantikan frequency & interval
+	BusinessDayShift     string     `json:"business_day_shift"`
+	StartDate            time.Time  `json:"start_date"`
+	NextDueDate          time.Time  `json:"next_due_date"`
+	EndDate              *time.Time `json:"end_date,omitempty"`        // Kejadian terakhir paling lambat tanggal ini
+	MaxOccurrences       *int       `json:"max_occurrences,omitempty"` // Berhenti setelah sekian kejadian
+	Occurrences          int        `json:"occurrences"`               // Jumlah kejadian yang sudah diposting atau dilewati
+	PausedAt             *time.Time `json:"paused_at,omitempty"`
+	LastError            *string    `json:"last_error,omitempty"` // Error terakhir dari worker, kosong jika berhasil
+	LastErrorAt          *time.Time `json:"last_error_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+
+	// Dihitung, tidak disimpan
+	Status string `json:"status"`
+}
+
+// RecurringRunResult adalah hasil satu kali worker transaksi berulang berjalan
+type RecurringRunResult struct {
+	Schedules int                 `json:"schedules"` // Jadwal jatuh tempo yang diproses
+	Posted    int                 `json:"posted"`    // Transaksi yang dibuat, termasuk kejadian yang terlewat
+	Failed    int                 `json:"failed"`
+	Errors    []RecurringRunError `json:"errors"`
+}
+
+// RecurringRunError mencatat jadwal yang gagal diproses. Jadwal lain tetap diproses.
+type RecurringRunError struct {
+	RecurringID int64  `json:"recurring_id"`
+	Error       string `json:"error"`
+}
+
+// RecurringRun adalah satu baris log worker transaksi berulang
+type RecurringRun struct {
+	ID         int64               `json:"id"`
+	Trigger    string              `json:"trigger"` // 'cron' atau 'manual'
+	Status     string              `json:"status"`  // 'running', 'completed' atau 'failed'
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt *time.Time          `json:"finished_at,omitempty"`
+	Schedules  int                 `json:"schedules"`
+	Posted     int                 `json:"posted"`
+	Failed     int                 `json:"failed"`
+	Errors     []RecurringRunError `json:"errors"`
+	Error      *string             `json:"error,omitempty"` // Error yang menghentikan seluruh run
+}
+
+// Holiday adalah hari libur yang dilewati jadwal dengan business_day_shift
+type Holiday struct {
+	Date      string    `json:"date"` // YYYY-MM-DD
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
 }
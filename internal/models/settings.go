@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// DefaultTimezone dipakai jika zona waktu aplikasi belum diatur
+const DefaultTimezone = "Asia/Jakarta"
+
+// AppSettings adalah pengaturan aplikasi (tabel app_settings) yang memengaruhi periode default
+// laporan. Berlaku untuk semua pengguna karena data keuangannya dipakai bersama.
+type AppSettings struct {
+	MonthStartDay int    `json:"month_start_day"` // Tanggal awal bulan keuangan (1-31), misal 25 untuk tanggal gajian
+	Timezone      string `json:"timezone"`        // Nama zona waktu IANA, misal "Asia/Jakarta"
+}
+
+// FinancialPeriod adalah batas satu bulan keuangan. Month & Year adalah bulan tempat periode dimulai.
+type FinancialPeriod struct {
+	Month         int       `json:"month"`
+	Year          int       `json:"year"`
+	MonthStartDay int       `json:"month_start_day"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"` // Inklusif
+	Days          int       `json:"days"`
+}
+
+// CalendarFeed adalah URL rahasia feed iCalendar seorang pengguna
+type CalendarFeed struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
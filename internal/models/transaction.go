@@ -16,6 +16,9 @@ type Transaction struct {
 
 	// Pointer *int64 agar bisa null/nil
 	DestinationAccountID *int64 `json:"destination_account_id,omitempty"`
+
+	// ID dari bank (misal FITID OFX), hanya terisi untuk transaksi hasil impor
+	ExternalID *string `json:"external_id,omitempty"`
 }
 
 type Summary struct {
@@ -23,3 +26,20 @@ type Summary struct {
 	TotalExpense int64 `json:"total_expense"` // Total Pengeluaran
 	NetBalance   int64 `json:"net_balance"`   // Pemasukan - Pengeluaran
 }
+
+// BatchTransactionRequest adalah payload untuk POST /api/transactions/batch
+type BatchTransactionRequest struct {
+	Transactions []Transaction `json:"transactions"`
+}
+
+// BatchRowError menjelaskan kenapa satu baris di dalam batch ditolak
+type BatchRowError struct {
+	Index int    `json:"index"`          // Posisi baris di array (mulai dari 0)
+	Line  int    `json:"line,omitempty"` // Nomor baris di file, untuk impor
+	Error string `json:"error"`
+}
+
+type BatchTransactionResponse struct {
+	Created int             `json:"created"`
+	Errors  []BatchRowError `json:"errors,omitempty"`
+}
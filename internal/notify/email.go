@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// EmailChannel mengirim notifikasi sebagai email teks biasa lewat SMTP
+type EmailChannel struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+// NewEmailChannel membuat channel email. Jika username kosong, SMTP dipakai tanpa autentikasi.
+func NewEmailChannel(host, port, username, password, from string, to []string) *EmailChannel {
+	ch := &EmailChannel{addr: net.JoinHostPort(host, port), from: from, to: to}
+	if username != "" {
+		ch.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return ch
+}
+
+func (c *EmailChannel) Name() string { return "email" }
+
+func (c *EmailChannel) Send(ctx context.Context, n models.Notification) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", c.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(c.to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Title))
+	fmt.Fprintf(&msg, "Date: %s\r\n", n.CreatedAt.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(n.Message, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	// smtp.SendMail tidak menerima context, jadi batas waktu dijaga dari luar
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(c.addr, c.auth, c.from, c.to, []byte(msg.String())) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
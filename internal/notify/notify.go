@@ -0,0 +1,93 @@
+// Package notify mengirim notifikasi aplikasi ke channel luar seperti email dan webhook.
+// Notifikasi in-app sendiri disimpan di database oleh package api.
+package notify
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bramszs/finance-tracker/internal/models"
+)
+
+// Channel adalah tujuan pengiriman notifikasi
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, n models.Notification) error
+}
+
+// Dispatcher meneruskan notifikasi ke semua channel yang terdaftar.
+// Dispatcher nil aman dipakai dan tidak mengirim apa-apa.
+type Dispatcher struct {
+	channels []Channel
+	timeout  time.Duration
+}
+
+func NewDispatcher(channels ...Channel) *Dispatcher {
+	return &Dispatcher{channels: channels, timeout: 30 * time.Second}
+}
+
+// Channels mengembalikan nama semua channel yang aktif
+func (d *Dispatcher) Channels() []string {
+	if d == nil {
+		return nil
+	}
+	names := make([]string, len(d.channels))
+	for i, ch := range d.channels {
+		names[i] = ch.Name()
+	}
+	return names
+}
+
+// Dispatch mengirim notifikasi ke setiap channel di background, agar request
+// tidak menunggu SMTP atau webhook. Kegagalan hanya dicatat di log.
+func (d *Dispatcher) Dispatch(n models.Notification) {
+	if d == nil {
+		return
+	}
+	for _, ch := range d.channels {
+		go func(ch Channel) {
+			ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+			defer cancel()
+			if err := ch.Send(ctx, n); err != nil {
+				log.Printf("Failed to send notification %d via %s: %v", n.ID, ch.Name(), err)
+			}
+		}(ch)
+	}
+}
+
+// FromEnv menyusun Dispatcher dari environment variable. Channel yang konfigurasinya
+// kosong dilewati:
+//
+//	NOTIFY_WEBHOOK_URL, NOTIFY_WEBHOOK_SECRET
+//	SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD, NOTIFY_EMAIL_FROM, NOTIFY_EMAIL_TO
+func FromEnv() *Dispatcher {
+	var channels []Channel
+
+	if url := os.Getenv("NOTIFY_WEBHOOK_URL"); url != "" {
+		channels = append(channels, NewWebhookChannel(url, os.Getenv("NOTIFY_WEBHOOK_SECRET")))
+	}
+
+	host, to := os.Getenv("SMTP_HOST"), os.Getenv("NOTIFY_EMAIL_TO")
+	if host != "" && to != "" {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		from := os.Getenv("NOTIFY_EMAIL_FROM")
+		if from == "" {
+			from = os.Getenv("SMTP_USERNAME")
+		}
+		var recipients []string
+		for _, addr := range strings.Split(to, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				recipients = append(recipients, addr)
+			}
+		}
+		channels = append(channels, NewEmailChannel(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from, recipients))
+	}
+
+	return NewDispatcher(channels...)
+}
@@ -0,0 +1,422 @@
+// Package recurrence mengurai dan menjalankan subset aturan pengulangan RFC 5545 (RRULE)
+// untuk jadwal transaksi berulang. Semua perhitungan memakai tanggal kalender saja;
+// jam dan zona waktu diabaikan.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Nilai FREQ yang didukung
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+	Yearly  = "YEARLY"
+)
+
+// maxPeriods membatasi pencarian kejadian, agar aturan yang tidak pernah cocok
+// (misal BYMONTH=2;BYMONTHDAY=30) tidak berputar selamanya
+const maxPeriods = 10000
+
+// WeekdayNum adalah satu nilai BYDAY, misal "2FR" (N = 2) atau "-1MO" (N = -1).
+// N = 0 berarti setiap hari itu di dalam periode.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule adalah hasil parse sebuah RRULE
+type Rule struct {
+	Freq       string
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int // Negatif dihitung dari akhir bulan (-1 = hari terakhir)
+	ByMonth    []int
+	BySetPos   []int // Negatif dihitung dari akhir periode
+	Until      *time.Time
+	Count      int
+}
+
+var allMonths = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+
+var dayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// Parse membaca RRULE seperti "FREQ=MONTHLY;BYDAY=FR;BYSETPOS=2". Awalan "RRULE:" boleh ada.
+// Bagian yang didukung: FREQ, INTERVAL, BYDAY, BYMONTHDAY, BYMONTH, BYSETPOS, UNTIL, COUNT
+// dan WKST=MO.
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(strings.ToUpper(s)), "RRULE:")
+	if s == "" {
+		return nil, errors.New("rrule is empty")
+	}
+
+	r := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid rrule part %q", part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("rrule part %s is repeated", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch value {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = value
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %q, use DAILY, WEEKLY, MONTHLY or YEARLY", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err != nil || r.Interval < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				wd, err := parseWeekdayNum(v)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseInts(key, value, 31)
+		case "BYMONTH":
+			r.ByMonth, err = parseInts(key, value, 12)
+			if err == nil && slices.ContainsFunc(r.ByMonth, func(m int) bool { return m < 0 }) {
+				err = fmt.Errorf("invalid BYMONTH %q", value)
+			}
+		case "BYSETPOS":
+			r.BySetPos, err = parseInts(key, value, 366)
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = &until
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err == nil && r.Count < 1 {
+				err = fmt.Errorf("invalid COUNT %q", value)
+			}
+		case "WKST":
+			if value != "MO" {
+				err = errors.New("only WKST=MO is supported")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rrule part %s", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, errors.New("rrule must contain FREQ")
+	}
+	if r.Until != nil && r.Count > 0 {
+		return nil, errors.New("rrule cannot contain both UNTIL and COUNT")
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return nil, errors.New("numbered BYDAY (e.g. 2FR) is only allowed with FREQ=MONTHLY or YEARLY")
+		}
+	}
+	if len(r.BySetPos) > 0 && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+		return nil, errors.New("BYSETPOS requires BYDAY or BYMONTHDAY")
+	}
+	return r, nil
+}
+
+func parseWeekdayNum(v string) (WeekdayNum, error) {
+	if len(v) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", v)
+	}
+	day, ok := dayCodes[v[len(v)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", v)
+	}
+	wd := WeekdayNum{Day: day}
+	if num := v[:len(v)-2]; num != "" {
+		n, err := strconv.Atoi(num)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", v)
+		}
+		wd.N = n
+	}
+	return wd, nil
+}
+
+// parseInts membaca daftar angka bukan nol antara -limit dan limit
+func parseInts(key, value string, limit int) ([]int, error) {
+	var out []int
+	for _, v := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil || n == 0 || n < -limit || n > limit {
+			return nil, fmt.Errorf("invalid %s %q", key, value)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102", "20060102T150405Z", "20060102T150405"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return civil(t), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q, use YYYYMMDD", value)
+}
+
+// String menulis aturan kembali dalam bentuk RRULE yang konsisten (tanpa awalan "RRULE:")
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			days[i] = strings.ToUpper(wd.Day.String()[:2])
+			if wd.N != 0 {
+				days[i] = strconv.Itoa(wd.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ",")
+}
+
+// civil mengambil tanggal kalender t sebagai jam 00:00 UTC
+func civil(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// After mengembalikan kejadian pertama yang jatuh SETELAH tanggal after, untuk jadwal yang
+// mulai pada start. Tanggal start hanya dihitung jika cocok dengan aturannya. Hasilnya
+// memakai zona waktu start; false jika tidak ada kejadian lagi (UNTIL/COUNT habis).
+func (r *Rule) After(start, after time.Time) (time.Time, bool) {
+	s, a := civil(start), civil(after)
+
+	// Tanpa COUNT, periode sebelum after tidak perlu dihitung satu per satu
+	period := 0
+	if r.Count == 0 && a.After(s) {
+		period = max(r.periodsBetween(s, a)-1, 0)
+	}
+
+	count := 0
+	for i := 0; i < maxPeriods; i, period = i+1, period+1 {
+		for _, c := range r.candidates(s, period) {
+			if c.Before(s) {
+				continue
+			}
+			if r.Until != nil && c.After(*r.Until) {
+				return time.Time{}, false
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return time.Time{}, false
+			}
+			if c.After(a) {
+				return time.Date(c.Year(), c.Month(), c.Day(), 0, 0, 0, 0, start.Location()), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// periodsBetween menghitung berapa periode (hari, minggu, bulan, tahun) dari s sampai a
+func (r *Rule) periodsBetween(s, a time.Time) int {
+	var n int
+	switch r.Freq {
+	case Daily:
+		n = int(a.Sub(s).Hours() / 24)
+	case Weekly:
+		n = int(weekStart(a).Sub(weekStart(s)).Hours() / (24 * 7))
+	case Monthly:
+		n = (a.Year()-s.Year())*12 + int(a.Month()-s.Month())
+	case Yearly:
+		n = a.Year() - s.Year()
+	}
+	return n / r.Interval
+}
+
+// weekStart mengembalikan hari Senin di minggu yang memuat t
+func weekStart(t time.Time) time.Time {
+	return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+}
+
+// candidates mengembalikan kejadian (terurut) di periode ke-n sejak s
+func (r *Rule) candidates(s time.Time, n int) []time.Time {
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		d := s.AddDate(0, 0, n*r.Interval)
+		if r.matchesMonth(d) && r.matchesMonthDay(d) && r.matchesWeekday(d) {
+			days = []time.Time{d}
+		}
+	case Weekly:
+		ws := weekStart(s).AddDate(0, 0, 7*n*r.Interval)
+		if len(r.ByDay) == 0 {
+			days = []time.Time{ws.AddDate(0, 0, (int(s.Weekday())+6)%7)}
+		} else {
+			for i := 0; i < 7; i++ {
+				if d := ws.AddDate(0, 0, i); r.matchesWeekday(d) {
+					days = append(days, d)
+				}
+			}
+		}
+		days = slices.DeleteFunc(days, func(d time.Time) bool { return !r.matchesMonth(d) })
+	case Monthly:
+		first := time.Date(s.Year(), s.Month()+time.Month(n*r.Interval), 1, 0, 0, 0, 0, time.UTC)
+		if r.matchesMonth(first) {
+			days = r.expandMonth(s, first.Year(), first.Month())
+		}
+	case Yearly:
+		year := s.Year() + n*r.Interval
+		if len(r.ByDay) > 0 && len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 {
+			days = r.expandYear(year) // Misal "20MO": Senin ke-20 dalam setahun
+			break
+		}
+		months := r.ByMonth
+		switch {
+		case len(months) > 0:
+		case len(r.ByMonthDay) > 0:
+			months = allMonths // BYMONTHDAY tanpa BYMONTH berlaku di setiap bulan
+		default:
+			months = []int{int(s.Month())}
+		}
+		for _, m := range slices.Sorted(slices.Values(months)) {
+			days = append(days, r.expandMonth(s, year, time.Month(m))...)
+		}
+	}
+	return r.applySetPos(days)
+}
+
+// expandMonth mengembalikan hari-hari di satu bulan yang cocok dengan BYMONTHDAY & BYDAY.
+// Tanpa keduanya dipakai tanggal start; tanggal yang tidak ada (misal 31) dimundurkan ke
+// hari terakhir bulan itu.
+func (r *Rule) expandMonth(s time.Time, year int, month time.Month) []time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		return []time.Time{time.Date(year, month, min(s.Day(), last), 0, 0, 0, 0, time.UTC)}
+	}
+
+	var days []time.Time
+	for day := 1; day <= last; day++ {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		if !r.matchesMonthDay(d) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !r.matchesNthWeekday(d, (day-1)/7+1, (last-day)/7+1) {
+			continue
+		}
+		days = append(days, d)
+	}
+	return days
+}
+
+// expandYear mengembalikan hari-hari di satu tahun yang cocok dengan BYDAY
+func (r *Rule) expandYear(year int) []time.Time {
+	var days []time.Time
+	total := time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	for yd := 1; yd <= total; yd++ {
+		d := time.Date(year, 1, yd, 0, 0, 0, 0, time.UTC)
+		if r.matchesNthWeekday(d, (yd-1)/7+1, (total-yd)/7+1) {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// matchesNthWeekday mencocokkan d dengan BYDAY; nth dan nthFromEnd adalah urutan hari
+// tersebut di periodenya (misal Jumat ke-2, atau Jumat ke-1 dari akhir)
+func (r *Rule) matchesNthWeekday(d time.Time, nth, nthFromEnd int) bool {
+	for _, wd := range r.ByDay {
+		if wd.Day != d.Weekday() {
+			continue
+		}
+		if wd.N == 0 || wd.N == nth || wd.N == -nthFromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesWeekday(d time.Time) bool {
+	return len(r.ByDay) == 0 || r.matchesNthWeekday(d, 0, 0)
+}
+
+func (r *Rule) matchesMonth(d time.Time) bool {
+	return len(r.ByMonth) == 0 || slices.Contains(r.ByMonth, int(d.Month()))
+}
+
+func (r *Rule) matchesMonthDay(d time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, md := range r.ByMonthDay {
+		if md == d.Day() || md == d.Day()-last-1 {
+			return true
+		}
+	}
+	return false
+}
+
+// applySetPos memilih kejadian ke-n dari satu periode sesuai BYSETPOS
+func (r *Rule) applySetPos(days []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(days) == 0 {
+		return days
+	}
+	var picked []time.Time
+	for _, pos := range r.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(days) + pos
+		}
+		if i >= 0 && i < len(days) && !slices.ContainsFunc(picked, days[i].Equal) {
+			picked = append(picked, days[i])
+		}
+	}
+	slices.SortFunc(picked, func(a, b time.Time) int { return a.Compare(b) })
+	return picked
+}
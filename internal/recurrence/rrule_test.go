@@ -0,0 +1,193 @@
+package recurrence
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRuleOccurrences(t *testing.T) {
+	tests := []struct {
+		name  string
+		rrule string
+		start string
+		n     int // Jumlah kejadian yang diminta; lebih dari len(want) untuk aturan yang habis
+		want  []string
+	}{
+		// Contoh dari RFC 5545 bagian 3.8.5.3 (tanpa jam; WKST=SU tidak didukung dan tidak
+		// mengubah hasil contoh yang dipakai di sini)
+		{"daily for 10 occurrences", "FREQ=DAILY;COUNT=10", "1997-09-02", 20, []string{
+			"1997-09-02", "1997-09-03", "1997-09-04", "1997-09-05", "1997-09-06",
+			"1997-09-07", "1997-09-08", "1997-09-09", "1997-09-10", "1997-09-11"}},
+		{"every other day", "FREQ=DAILY;INTERVAL=2", "1997-09-02", 4, []string{
+			"1997-09-02", "1997-09-04", "1997-09-06", "1997-09-08"}},
+		{"every 10 days, 5 occurrences", "FREQ=DAILY;INTERVAL=10;COUNT=5", "1997-09-02", 10, []string{
+			"1997-09-02", "1997-09-12", "1997-09-22", "1997-10-02", "1997-10-12"}},
+		{"weekly for 10 occurrences", "FREQ=WEEKLY;COUNT=10", "1997-09-02", 20, []string{
+			"1997-09-02", "1997-09-09", "1997-09-16", "1997-09-23", "1997-09-30",
+			"1997-10-07", "1997-10-14", "1997-10-21", "1997-10-28", "1997-11-04"}},
+		{"weekly on Tuesday and Thursday for five weeks", "FREQ=WEEKLY;COUNT=10;BYDAY=TU,TH", "1997-09-02", 20, []string{
+			"1997-09-02", "1997-09-04", "1997-09-09", "1997-09-11", "1997-09-16",
+			"1997-09-18", "1997-09-23", "1997-09-25", "1997-09-30", "1997-10-02"}},
+		// RFC memakai UNTIL=19971224T000000Z dengan DTSTART jam 09:00 sehingga 24 Desember
+		// tidak ikut; di sini UNTIL hanya tanggal dan inklusif
+		{"every other week on Monday, Wednesday and Friday until December 24", "FREQ=WEEKLY;INTERVAL=2;UNTIL=19971224;BYDAY=MO,WE,FR", "1997-09-01", 50, []string{
+			"1997-09-01", "1997-09-03", "1997-09-05", "1997-09-15", "1997-09-17",
+			"1997-09-19", "1997-09-29", "1997-10-01", "1997-10-03", "1997-10-13",
+			"1997-10-15", "1997-10-17", "1997-10-27", "1997-10-29", "1997-10-31",
+			"1997-11-10", "1997-11-12", "1997-11-14", "1997-11-24", "1997-11-26",
+			"1997-11-28", "1997-12-08", "1997-12-10", "1997-12-12", "1997-12-22", "1997-12-24"}},
+		{"every other week on Tuesday and Thursday for 8 occurrences", "FREQ=WEEKLY;INTERVAL=2;COUNT=8;BYDAY=TU,TH", "1997-09-02", 20, []string{
+			"1997-09-02", "1997-09-04", "1997-09-16", "1997-09-18",
+			"1997-09-30", "1997-10-02", "1997-10-14", "1997-10-16"}},
+		{"monthly on the first Friday for 10 occurrences", "FREQ=MONTHLY;COUNT=10;BYDAY=1FR", "1997-09-05", 20, []string{
+			"1997-09-05", "1997-10-03", "1997-11-07", "1997-12-05", "1998-01-02",
+			"1998-02-06", "1998-03-06", "1998-04-03", "1998-05-01", "1998-06-05"}},
+		{"every other month on the first and last Sunday", "FREQ=MONTHLY;INTERVAL=2;COUNT=10;BYDAY=1SU,-1SU", "1997-09-07", 20, []string{
+			"1997-09-07", "1997-09-28", "1997-11-02", "1997-11-30", "1998-01-04",
+			"1998-01-25", "1998-03-01", "1998-03-29", "1998-05-03", "1998-05-31"}},
+		{"monthly on the second-to-last Monday for 6 months", "FREQ=MONTHLY;COUNT=6;BYDAY=-2MO", "1997-09-22", 10, []string{
+			"1997-09-22", "1997-10-20", "1997-11-17", "1997-12-22", "1998-01-19", "1998-02-16"}},
+		{"monthly on the third-to-last day", "FREQ=MONTHLY;BYMONTHDAY=-3", "1997-09-28", 6, []string{
+			"1997-09-28", "1997-10-29", "1997-11-28", "1997-12-29", "1998-01-29", "1998-02-26"}},
+		{"monthly on the 2nd and 15th for 10 occurrences", "FREQ=MONTHLY;COUNT=10;BYMONTHDAY=2,15", "1997-09-02", 20, []string{
+			"1997-09-02", "1997-09-15", "1997-10-02", "1997-10-15", "1997-11-02",
+			"1997-11-15", "1997-12-02", "1997-12-15", "1998-01-02", "1998-01-15"}},
+		{"monthly on the first and last day for 10 occurrences", "FREQ=MONTHLY;COUNT=10;BYMONTHDAY=1,-1", "1997-09-30", 20, []string{
+			"1997-09-30", "1997-10-01", "1997-10-31", "1997-11-01", "1997-11-30",
+			"1997-12-01", "1997-12-31", "1998-01-01", "1998-01-31", "1998-02-01"}},
+		{"every 18 months on the 10th through 15th", "FREQ=MONTHLY;INTERVAL=18;COUNT=10;BYMONTHDAY=10,11,12,13,14,15", "1997-09-10", 20, []string{
+			"1997-09-10", "1997-09-11", "1997-09-12", "1997-09-13", "1997-09-14",
+			"1997-09-15", "1999-03-10", "1999-03-11", "1999-03-12", "1999-03-13"}},
+		{"every Tuesday, every other month", "FREQ=MONTHLY;INTERVAL=2;BYDAY=TU", "1997-09-02", 10, []string{
+			"1997-09-02", "1997-09-09", "1997-09-16", "1997-09-23", "1997-09-30",
+			"1997-11-04", "1997-11-11", "1997-11-18", "1997-11-25", "1998-01-06"}},
+		{"yearly in June and July for 10 occurrences", "FREQ=YEARLY;COUNT=10;BYMONTH=6,7", "1997-06-10", 20, []string{
+			"1997-06-10", "1997-07-10", "1998-06-10", "1998-07-10", "1999-06-10",
+			"1999-07-10", "2000-06-10", "2000-07-10", "2001-06-10", "2001-07-10"}},
+		{"every other year on January, February and March", "FREQ=YEARLY;INTERVAL=2;COUNT=10;BYMONTH=1,2,3", "1997-03-10", 20, []string{
+			"1997-03-10", "1999-01-10", "1999-02-10", "1999-03-10", "2001-01-10",
+			"2001-02-10", "2001-03-10", "2003-01-10", "2003-02-10", "2003-03-10"}},
+		{"every 20th Monday of the year", "FREQ=YEARLY;BYDAY=20MO", "1997-05-19", 3, []string{
+			"1997-05-19", "1998-05-18", "1999-05-17"}},
+		{"every Thursday in March", "FREQ=YEARLY;BYMONTH=3;BYDAY=TH", "1997-03-13", 11, []string{
+			"1997-03-13", "1997-03-20", "1997-03-27", "1998-03-05", "1998-03-12", "1998-03-19",
+			"1998-03-26", "1999-03-04", "1999-03-11", "1999-03-18", "1999-03-25"}},
+		{"every Thursday in June, July and August", "FREQ=YEARLY;BYDAY=TH;BYMONTH=6,7,8", "1997-06-05", 14, []string{
+			"1997-06-05", "1997-06-12", "1997-06-19", "1997-06-26", "1997-07-03",
+			"1997-07-10", "1997-07-17", "1997-07-24", "1997-07-31", "1997-08-07",
+			"1997-08-14", "1997-08-21", "1997-08-28", "1998-06-04"}},
+		{"every Friday the 13th", "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", "1997-09-02", 5, []string{
+			"1998-02-13", "1998-03-13", "1998-11-13", "1999-08-13", "2000-10-13"}},
+		{"first Saturday that follows the first Sunday", "FREQ=MONTHLY;BYDAY=SA;BYMONTHDAY=7,8,9,10,11,12,13", "1997-09-13", 10, []string{
+			"1997-09-13", "1997-10-11", "1997-11-08", "1997-12-13", "1998-01-10",
+			"1998-02-07", "1998-03-07", "1998-04-11", "1998-05-09", "1998-06-13"}},
+		{"US presidential election day", "FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=TU;BYMONTHDAY=2,3,4,5,6,7,8", "1996-11-05", 3, []string{
+			"1996-11-05", "2000-11-07", "2004-11-02"}},
+		{"third Tuesday, Wednesday or Thursday of the month", "FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3", "1997-09-04", 10, []string{
+			"1997-09-04", "1997-10-07", "1997-11-06"}},
+		{"second-to-last weekday of the month", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-2", "1997-09-29", 7, []string{
+			"1997-09-29", "1997-10-30", "1997-11-27", "1997-12-30", "1998-01-29", "1998-02-26", "1998-03-30"}},
+		{"invalid dates such as February 30 are ignored", "FREQ=MONTHLY;BYMONTHDAY=15,30;COUNT=5", "2007-01-15", 10, []string{
+			"2007-01-15", "2007-01-30", "2007-02-15", "2007-03-15", "2007-03-30"}},
+
+		// Pola tagihan dari permintaan fitur
+		{"second Friday", "FREQ=MONTHLY;BYDAY=2FR", "2026-01-01", 4, []string{
+			"2026-01-09", "2026-02-13", "2026-03-13", "2026-04-10"}},
+		{"last business day", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", "2026-01-01", 5, []string{
+			"2026-01-30", "2026-02-27", "2026-03-31", "2026-04-30", "2026-05-29"}},
+		{"15th and end of month", "FREQ=MONTHLY;BYMONTHDAY=15,-1", "2026-01-01", 6, []string{
+			"2026-01-15", "2026-01-31", "2026-02-15", "2026-02-28", "2026-03-15", "2026-03-31"}},
+
+		// BYMONTHDAY tanpa BYMONTH pada FREQ=YEARLY berlaku di setiap bulan
+		{"yearly BYMONTHDAY without BYMONTH", "FREQ=YEARLY;BYMONTHDAY=15", "2026-11-01", 4, []string{
+			"2026-11-15", "2026-12-15", "2027-01-15", "2027-02-15"}},
+		// Tanpa BYxxx, tanggal start yang tidak ada di bulan pendek dimundurkan ke akhir bulan
+		{"monthly on the 31st clamps to month end", "FREQ=MONTHLY", "2026-01-31", 4, []string{
+			"2026-01-31", "2026-02-28", "2026-03-31", "2026-04-30"}},
+		// UNTIL ikut dihitung (inklusif)
+		{"until is inclusive", "FREQ=WEEKLY;UNTIL=20260115", "2026-01-01", 10, []string{
+			"2026-01-01", "2026-01-08", "2026-01-15"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rrule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rrule, err)
+			}
+			start := mustDate(t, tt.start)
+
+			got := make([]string, 0, len(tt.want))
+			for after := start.AddDate(0, 0, -1); len(got) < tt.n; {
+				next, ok := rule.After(start, after)
+				if !ok {
+					break
+				}
+				got = append(got, next.Format("2006-01-02"))
+				after = next
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("occurrences of %s from %s\n got %v\nwant %v", tt.rrule, tt.start, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleAfterSkipsAhead(t *testing.T) {
+	// Pencarian dari tengah deret harus sama dengan menelusurinya dari awal
+	rule, err := Parse("FREQ=MONTHLY;BYDAY=2FR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := mustDate(t, "2020-01-01")
+	next, ok := rule.After(start, mustDate(t, "2026-03-13"))
+	if !ok || next.Format("2006-01-02") != "2026-04-10" {
+		t.Errorf("After = %s, %v; want 2026-04-10", next.Format("2006-01-02"), ok)
+	}
+	if next.Location() != start.Location() {
+		t.Errorf("After location = %s, want %s", next.Location(), start.Location())
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		rrule string
+		want  string // Kosong jika harus gagal
+	}{
+		{"RRULE:freq=monthly;byday=2fr", "FREQ=MONTHLY;BYDAY=2FR"},
+		{"FREQ=MONTHLY;INTERVAL=1;BYMONTHDAY=-1,15", "FREQ=MONTHLY;BYMONTHDAY=-1,15"},
+		{"FREQ=YEARLY;UNTIL=20261231T235959Z", "FREQ=YEARLY;UNTIL=20261231"},
+		{"FREQ=WEEKLY;WKST=MO;COUNT=3", "FREQ=WEEKLY;COUNT=3"},
+		{"", ""},
+		{"BYDAY=FR", ""},
+		{"FREQ=HOURLY", ""},
+		{"FREQ=WEEKLY;BYDAY=2FR", ""},
+		{"FREQ=MONTHLY;COUNT=2;UNTIL=20260101", ""},
+		{"FREQ=MONTHLY;BYSETPOS=1", ""},
+		{"FREQ=MONTHLY;BYMONTHDAY=32", ""},
+		{"FREQ=MONTHLY;FREQ=DAILY", ""},
+		{"FREQ=WEEKLY;WKST=SU", ""},
+	}
+	for _, tt := range tests {
+		rule, err := Parse(tt.rrule)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("Parse(%q) = %s, want error", tt.rrule, rule)
+			}
+			continue
+		}
+		if err != nil || rule.String() != tt.want {
+			t.Errorf("Parse(%q) = %v, %v; want %s", tt.rrule, rule, err, tt.want)
+		}
+	}
+}
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
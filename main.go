@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"github.com/gorilla/mux"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -11,18 +12,27 @@ import (
 	"log"
 	"net/http"
 	"os"
+	_ "time/tzdata" // Zona waktu aplikasi tetap bisa dimuat di image tanpa tzdata
 
 	"github.com/bramszs/finance-tracker/internal/api"
+	"github.com/bramszs/finance-tracker/internal/notify"
 )
 
 func runCronJob(store *api.Store) {
 	log.Println("Running recurring transactions worker...")
-	count, err := store.ProcessRecurringTransactions(context.Background())
+	run, err := store.RunRecurringTransactions(context.Background(), api.RecurringTriggerCron)
+	if errors.Is(err, api.ErrRecurringRunInProgress) {
+		log.Println("Recurring transactions are being processed by another instance, skipping.")
+		return
+	}
 	if err != nil {
 		log.Printf("Error processing recurring transactions: %v", err)
 		return
 	}
-	log.Printf("Successfully processed %d recurring transactions.", count)
+	for _, e := range run.Errors {
+		log.Printf("Recurring transaction %d failed: %s", e.RecurringID, e.Error)
+	}
+	log.Printf("Recurring run %d: %d schedules, %d transactions posted, %d failed.", run.ID, run.Schedules, run.Posted, run.Failed)
 }
 
 func loggingMiddleware(next http.Handler) http.Handler {
@@ -55,6 +65,8 @@ func main() {
 	}
 	defer pool.Close()
 	store := api.NewStore(pool, jwtSecret)
+	store.Notifier = notify.FromEnv()
+	log.Printf("External notification channels: %v", store.Notifier.Channels())
 
 	cr := cron.New()
 	cr.AddFunc("0 1 * * *", func() { runCronJob(store) })
@@ -64,13 +76,17 @@ func main() {
 	r := mux.NewRouter()
 
 	apiRouter := r.PathPrefix("/api").Subrouter()
+	apiRouter.Use(store.SettingsMiddleware)
 	apiRouter.HandleFunc("/transactions", store.HandleGetTransactions).Methods("GET")
 	apiRouter.HandleFunc("/transactions", store.CreateTransactionHandler).Methods("POST")
+	apiRouter.HandleFunc("/transactions/batch", store.HandleCreateTransactionsBatch).Methods("POST")
 
 	apiRouter.HandleFunc("/summary", store.GetSummaryHandler).Methods("GET")
 	apiRouter.HandleFunc("/categories", store.GetCategoriesHandler).Methods("GET")
 	apiRouter.HandleFunc("/categories", store.CreateCategoryHandler).Methods("POST")
 	apiRouter.HandleFunc("/summary/categories", store.GetCategorySummaryHandler).Methods("GET")
+	apiRouter.HandleFunc("/forecast", store.HandleGetForecast).Methods("GET")
+	apiRouter.HandleFunc("/reports/statement.pdf", store.HandleStatementPDF).Methods("GET")
 
 	apiRouter.HandleFunc("/transactions/{id:[0-9]+}", store.DeleteTransactionHandler).Methods("DELETE")
 	apiRouter.HandleFunc("/transactions/{id:[0-9]+}", store.GetTransactionByIDHandler).Methods("GET")
@@ -78,10 +94,40 @@ func main() {
 
 	apiRouter.HandleFunc("/budgets", store.HandleGetBudgets).Methods("GET")
 	apiRouter.HandleFunc("/budgets", store.HandleSetBudget).Methods("POST")
+	apiRouter.HandleFunc("/budgets/progress", store.HandleGetBudgetProgress).Methods("GET")
+	apiRouter.HandleFunc("/budgets/alerts", store.HandleGetBudgetAlerts).Methods("GET")
+	apiRouter.HandleFunc("/budgets/copy", store.HandleCopyBudgets).Methods("POST")
+	apiRouter.HandleFunc("/budgets/templates", store.HandleGetBudgetTemplates).Methods("GET")
+	apiRouter.HandleFunc("/budgets/templates", store.HandleCreateBudgetTemplate).Methods("POST")
+	apiRouter.HandleFunc("/budgets/templates/{id:[0-9]+}", store.HandleGetBudgetTemplate).Methods("GET")
+	apiRouter.HandleFunc("/budgets/templates/{id:[0-9]+}", store.HandleDeleteBudgetTemplate).Methods("DELETE")
+	apiRouter.HandleFunc("/budgets/templates/{id:[0-9]+}/apply", store.HandleApplyBudgetTemplate).Methods("POST")
+	apiRouter.HandleFunc("/budgets/{year:[0-9]+}/{month:[0-9]+}", store.HandleSetMonthBudgets).Methods("PUT")
+
+	apiRouter.HandleFunc("/notifications", store.HandleGetNotifications).Methods("GET")
+	apiRouter.HandleFunc("/notifications/read-all", store.HandleMarkAllNotificationsRead).Methods("POST")
+	apiRouter.HandleFunc("/notifications/{id:[0-9]+}/read", store.HandleMarkNotificationRead).Methods("POST")
+
+	apiRouter.HandleFunc("/envelopes", store.HandleGetEnvelopes).Methods("GET")
+	apiRouter.HandleFunc("/envelopes/assign", store.HandleAssignEnvelope).Methods("POST")
+	apiRouter.HandleFunc("/envelopes/move", store.HandleMoveEnvelope).Methods("POST")
+	apiRouter.HandleFunc("/envelopes/cover", store.HandleCoverOverspending).Methods("POST")
 
 	apiRouter.HandleFunc("/recurring", store.HandleGetRecurringTransactions).Methods("GET")
 	apiRouter.HandleFunc("/recurring", store.HandleCreateRecurringTransaction).Methods("POST")
+	apiRouter.HandleFunc("/recurring/upcoming", store.HandleGetUpcomingRecurring).Methods("GET")
 	apiRouter.HandleFunc("/recurring/{id:[0-9]+}", store.HandleDeleteRecurringTransaction).Methods("DELETE")
+	apiRouter.HandleFunc("/recurring/{id:[0-9]+}", store.HandleUpdateRecurringTransaction).Methods("PUT", "PATCH")
+	apiRouter.HandleFunc("/recurring/{id:[0-9]+}/pause", store.HandlePauseRecurringTransaction).Methods("POST")
+	apiRouter.HandleFunc("/recurring/{id:[0-9]+}/resume", store.HandleResumeRecurringTransaction).Methods("POST")
+	apiRouter.HandleFunc("/recurring/{id:[0-9]+}/skip", store.HandleSkipRecurringOccurrence).Methods("POST")
+
+	apiRouter.HandleFunc("/admin/recurring/run", store.HandleRunRecurring).Methods("POST")
+	apiRouter.HandleFunc("/admin/recurring/run", store.HandleGetRecurringRuns).Methods("GET")
+
+	apiRouter.HandleFunc("/holidays", store.HandleGetHolidays).Methods("GET")
+	apiRouter.HandleFunc("/holidays", store.HandleSetHoliday).Methods("POST")
+	apiRouter.HandleFunc("/holidays/{date:[0-9]{4}-[0-9]{2}-[0-9]{2}}", store.HandleDeleteHoliday).Methods("DELETE")
 
 	apiRouter.HandleFunc("/accounts", store.HandleGetAccounts).Methods("GET")
 	apiRouter.HandleFunc("/accounts", store.HandleCreateAccount).Methods("POST")
@@ -89,13 +135,36 @@ func main() {
 	apiRouter.HandleFunc("/transfers", store.HandleCreateTransfer).Methods("POST")
 
 	apiRouter.HandleFunc("/export/csv", store.HandleExportCSV).Methods("GET")
+	apiRouter.HandleFunc("/export/ledger", store.HandleExportLedger).Methods("GET")
+	apiRouter.HandleFunc("/export/beancount", store.HandleExportBeancount).Methods("GET")
+	apiRouter.HandleFunc("/export/xlsx", store.HandleExportXLSX).Methods("GET")
+
+	apiRouter.HandleFunc("/import/csv/preview", store.HandlePreviewCSVImport).Methods("POST")
+	apiRouter.HandleFunc("/import/csv", store.HandleCommitCSVImport).Methods("POST")
+	apiRouter.HandleFunc("/import/ofx/preview", store.HandlePreviewOFXImport).Methods("POST")
+	apiRouter.HandleFunc("/import/ofx", store.HandleCommitOFXImport).Methods("POST")
+	apiRouter.HandleFunc("/import/statement/preview", store.HandlePreviewStatementImport).Methods("POST")
+	apiRouter.HandleFunc("/import/statement", store.HandleCommitStatementImport).Methods("POST")
+	apiRouter.HandleFunc("/import/formats", store.HandleGetImportFormats).Methods("GET")
+
+	apiRouter.HandleFunc("/backup", store.HandleBackup).Methods("GET")
+	apiRouter.HandleFunc("/restore", store.HandleRestore).Methods("POST")
 
 	apiRouter.HandleFunc("/auth/register", store.HandleRegister).Methods("POST")
 	apiRouter.HandleFunc("/auth/login", store.HandleLogin).Methods("POST")
 
+	apiRouter.HandleFunc("/settings", store.HandleGetSettings).Methods("GET")
+	apiRouter.HandleFunc("/settings", store.HandleUpdateSettings).Methods("PUT")
+	apiRouter.HandleFunc("/settings/calendar", store.HandleGetCalendarFeed).Methods("GET")
+	apiRouter.HandleFunc("/settings/calendar", store.HandleRotateCalendarToken).Methods("POST")
+	apiRouter.HandleFunc("/settings/calendar", store.HandleDisableCalendarFeed).Methods("DELETE")
+	apiRouter.HandleFunc("/calendar/{token:[A-Za-z0-9_-]+}.ics", store.HandleCalendarFeed).Methods("GET")
+	apiRouter.HandleFunc("/periods", store.HandleGetPeriods).Methods("GET")
+	apiRouter.HandleFunc("/periods/current", store.HandleGetCurrentPeriod).Methods("GET")
+
 	c := cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders: []string{"Content-Type", "Authorization"},
 	})
 	loggedRouter := loggingMiddleware(r)